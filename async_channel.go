@@ -0,0 +1,273 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// ErrAsyncChannelClosed is returned when writing to a closed AsyncChannel.
+var ErrAsyncChannelClosed = errors.New("async channel is closed")
+
+// BackpressurePolicy decides what AsyncChannel.Write does when the queue
+// is full.
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock makes Write wait until there is room in the queue.
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDrop makes Write drop the message and count it. Opened and
+	// closed messages are never dropped.
+	BackpressureDrop
+)
+
+const (
+	defaultAsyncQueueSize = 1024
+	defaultAsyncBatchSize = 64
+)
+
+// AsyncChannelOptions configures an AsyncChannel.
+type AsyncChannelOptions struct {
+	// QueueSize is the number of messages that can wait to be written.
+	// Defaults to 1024.
+	QueueSize int
+	// BatchSize is the maximum number of messages written per batch.
+	// Defaults to 64.
+	BatchSize int
+	// Policy is applied when the queue is full. Defaults to BackpressureBlock.
+	Policy BackpressurePolicy
+}
+
+// AsyncChannel wraps a MessageWriterChannel so that Write only queues the
+// message. A background goroutine writes queued messages to the wrapped
+// channel in batches: with a single WriteBatch call when the wrapped channel
+// is a BatchWriterChannel, such as FileChannel and StreamChannel, and one
+// message at a time otherwise.
+//
+// Use an AsyncMessageWriter to open sessions with an AsyncChannel.
+//
+// Errors from the wrapped channel are deferred and returned by Flush or
+// Close. Closing the channel writes every queued message, then closes the
+// wrapped channel.
+type AsyncChannel struct {
+	channel   MessageWriterChannel
+	policy    BackpressurePolicy
+	batchSize int
+
+	queue   chan *types.PipesMessage
+	stopped chan struct{}
+	dropped atomic.Int64
+
+	// closeMu guards queue against sends after it is closed.
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu      sync.Mutex
+	drained *sync.Cond
+	pending int
+	errs    []error
+}
+
+// NewAsyncChannel creates an AsyncChannel that writes to channel and starts
+// its background writer.
+func NewAsyncChannel(channel MessageWriterChannel, options AsyncChannelOptions) *AsyncChannel {
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultAsyncQueueSize
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultAsyncBatchSize
+	}
+
+	async := &AsyncChannel{
+		channel:   channel,
+		policy:    options.Policy,
+		batchSize: options.BatchSize,
+		queue:     make(chan *types.PipesMessage, options.QueueSize),
+		stopped:   make(chan struct{}),
+	}
+	async.drained = sync.NewCond(&async.mu)
+	go async.writeLoop()
+	return async
+}
+
+func (async *AsyncChannel) Write(message *types.PipesMessage) error {
+	async.closeMu.RLock()
+	defer async.closeMu.RUnlock()
+	if async.closed {
+		return ErrAsyncChannelClosed
+	}
+
+	async.mu.Lock()
+	async.pending++
+	async.mu.Unlock()
+
+	if async.policy == BackpressureDrop && message.Method != types.Opened && message.Method != types.Closed {
+		select {
+		case async.queue <- message:
+		default:
+			async.dropped.Add(1)
+			async.done(1)
+		}
+		return nil
+	}
+
+	async.queue <- message
+	return nil
+}
+
+// WriteContext is like Write but stops waiting for room in the queue when
+// ctx is done.
+func (async *AsyncChannel) WriteContext(ctx context.Context, message *types.PipesMessage) error {
+	if async.policy == BackpressureDrop {
+		return async.Write(message)
+	}
+
+	async.closeMu.RLock()
+	defer async.closeMu.RUnlock()
+	if async.closed {
+		return ErrAsyncChannelClosed
+	}
+
+	async.mu.Lock()
+	async.pending++
+	async.mu.Unlock()
+
+	select {
+	case async.queue <- message:
+		return nil
+	case <-ctx.Done():
+		async.done(1)
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of messages dropped by BackpressureDrop.
+func (async *AsyncChannel) Dropped() int64 {
+	return async.dropped.Load()
+}
+
+// Flush waits until every queued message has been written, flushes the
+// wrapped channel and returns the write errors deferred so far.
+func (async *AsyncChannel) Flush() error {
+	async.mu.Lock()
+	for async.pending > 0 {
+		async.drained.Wait()
+	}
+	err := async.takeErrors()
+	async.mu.Unlock()
+	return errors.Join(err, async.channel.Flush())
+}
+
+// Close writes the remaining queued messages, closes the wrapped channel and
+// returns every deferred write error.
+func (async *AsyncChannel) Close() error {
+	async.closeMu.Lock()
+	if !async.closed {
+		async.closed = true
+		close(async.queue)
+	}
+	async.closeMu.Unlock()
+	<-async.stopped
+
+	closeErr := async.channel.Close()
+
+	async.mu.Lock()
+	defer async.mu.Unlock()
+	return errors.Join(async.takeErrors(), closeErr)
+}
+
+func (async *AsyncChannel) writeLoop() {
+	defer close(async.stopped)
+
+	batch := make([]*types.PipesMessage, 0, async.batchSize)
+	for message := range async.queue {
+		batch = append(batch[:0], message)
+	collect:
+		for len(batch) < async.batchSize {
+			select {
+			case message, ok := <-async.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, message)
+			default:
+				break collect
+			}
+		}
+		async.writeBatch(batch)
+	}
+}
+
+func (async *AsyncChannel) writeBatch(batch []*types.PipesMessage) {
+	var errs []error
+	if channel, ok := async.channel.(BatchWriterChannel); ok {
+		errs = append(errs, channel.WriteBatch(batch))
+	} else {
+		for _, message := range batch {
+			errs = append(errs, async.channel.Write(message))
+		}
+	}
+
+	async.mu.Lock()
+	async.errs = append(async.errs, errs...)
+	async.mu.Unlock()
+	async.done(len(batch))
+}
+
+// done marks n queued messages as handled.
+func (async *AsyncChannel) done(n int) {
+	async.mu.Lock()
+	defer async.mu.Unlock()
+	async.pending -= n
+	if async.pending == 0 {
+		async.drained.Broadcast()
+	}
+}
+
+// takeErrors returns the deferred errors and forgets them. The caller must
+// hold async.mu.
+func (async *AsyncChannel) takeErrors() error {
+	err := errors.Join(async.errs...)
+	async.errs = nil
+	return err
+}
+
+// AsyncMessageWriter wraps a MessageWriter so that the channels it opens are
+// AsyncChannels. Pass it to NewPipesContext to make reports return without
+// waiting for the message to be written:
+//
+//	writer := dagster_pipes.NewAsyncMessageWriter(
+//	    dagster_pipes.NewDefaultMessageWriter(),
+//	    dagster_pipes.AsyncChannelOptions{Policy: dagster_pipes.BackpressureDrop},
+//	)
+//	context, err := dagster_pipes.NewPipesContext(contextData, messageParams, writer)
+type AsyncMessageWriter struct {
+	Writer  MessageWriter
+	Options AsyncChannelOptions
+}
+
+// NewAsyncMessageWriter creates an AsyncMessageWriter that wraps the
+// channels opened by writer.
+func NewAsyncMessageWriter(writer MessageWriter, options AsyncChannelOptions) *AsyncMessageWriter {
+	return &AsyncMessageWriter{Writer: writer, Options: options}
+}
+
+func (writer *AsyncMessageWriter) Open(params map[string]json.RawMessage) (MessageWriterChannel, error) {
+	channel, err := writer.Writer.Open(params)
+	if err != nil {
+		return nil, err
+	}
+	return NewAsyncChannel(channel, writer.Options), nil
+}
+
+func (writer *AsyncMessageWriter) GetOpenedPayload() map[string]any {
+	return writer.Writer.GetOpenedPayload()
+}
+
+func (writer *AsyncMessageWriter) GetOpenedExtras() map[string]any {
+	return writer.Writer.GetOpenedExtras()
+}
@@ -0,0 +1,174 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// recordingChannel records written messages. Writes wait for release when
+// it is set.
+type recordingChannel struct {
+	release chan struct{}
+	err     error
+
+	mu       sync.Mutex
+	messages []*types.PipesMessage
+	closed   bool
+}
+
+func (channel *recordingChannel) Write(message *types.PipesMessage) error {
+	if channel.release != nil {
+		<-channel.release
+	}
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	channel.messages = append(channel.messages, message)
+	return channel.err
+}
+
+func (channel *recordingChannel) Flush() error {
+	return nil
+}
+
+func (channel *recordingChannel) Close() error {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	channel.closed = true
+	return nil
+}
+
+// batchRecordingChannel is a recordingChannel that also records the size of
+// the batches it receives through WriteBatch.
+type batchRecordingChannel struct {
+	recordingChannel
+	batches []int
+}
+
+func (channel *batchRecordingChannel) WriteBatch(messages []*types.PipesMessage) error {
+	if channel.release != nil {
+		<-channel.release
+	}
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	channel.messages = append(channel.messages, messages...)
+	channel.batches = append(channel.batches, len(messages))
+	return channel.err
+}
+
+func TestAsyncChannel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes messages in order", func(t *testing.T) {
+		t.Parallel()
+		inner := &recordingChannel{}
+		channel := NewAsyncChannel(inner, AsyncChannelOptions{BatchSize: 4})
+		context := &PipesContext{Channel: channel, Data: &types.PipesContextData{}}
+
+		for i := range 10 {
+			err := context.ReportCustomMessage(i)
+			require.NoError(t, err)
+		}
+		err := context.Close(nil)
+		require.NoError(t, err)
+
+		require.True(t, inner.closed)
+		require.Len(t, inner.messages, 11)
+		for i, message := range inner.messages[:10] {
+			require.Equal(t, i, message.Params["payload"])
+		}
+		require.Equal(t, types.Closed, inner.messages[10].Method)
+	})
+
+	t.Run("drops messages when the queue is full", func(t *testing.T) {
+		t.Parallel()
+		inner := &recordingChannel{release: make(chan struct{})}
+		channel := NewAsyncChannel(inner, AsyncChannelOptions{QueueSize: 1, Policy: BackpressureDrop})
+
+		for range 10 {
+			err := channel.Write(types.NewMessage(types.ReportCustomMessage, nil))
+			require.NoError(t, err)
+		}
+		require.Positive(t, channel.Dropped())
+
+		close(inner.release)
+		err := channel.Close()
+		require.NoError(t, err)
+		require.Equal(t, int64(10), int64(len(inner.messages))+channel.Dropped())
+	})
+
+	t.Run("defers write errors", func(t *testing.T) {
+		t.Parallel()
+		writeErr := errors.New("write failed")
+		inner := &recordingChannel{err: writeErr}
+		channel := NewAsyncChannel(inner, AsyncChannelOptions{})
+
+		err := channel.Write(types.NewMessage(types.Opened, nil))
+		require.NoError(t, err)
+		err = channel.Flush()
+		require.ErrorIs(t, err, writeErr)
+
+		err = channel.Write(types.NewMessage(types.Closed, nil))
+		require.NoError(t, err)
+		err = channel.Close()
+		require.ErrorIs(t, err, writeErr)
+
+		err = channel.Write(types.NewMessage(types.Closed, nil))
+		require.ErrorIs(t, err, ErrAsyncChannelClosed)
+	})
+
+	t.Run("hands batches to a batch writer", func(t *testing.T) {
+		t.Parallel()
+		inner := &batchRecordingChannel{recordingChannel: recordingChannel{release: make(chan struct{})}}
+		channel := NewAsyncChannel(inner, AsyncChannelOptions{BatchSize: 4})
+
+		for range 10 {
+			err := channel.Write(types.NewMessage(types.ReportCustomMessage, nil))
+			require.NoError(t, err)
+		}
+		close(inner.release)
+		err := channel.Close()
+		require.NoError(t, err)
+
+		require.Len(t, inner.messages, 10)
+		require.Less(t, len(inner.batches), 10)
+		for _, size := range inner.batches {
+			require.LessOrEqual(t, size, 4)
+		}
+	})
+}
+
+func TestAsyncMessageWriter(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "messages")
+	writer := NewAsyncMessageWriter(NewDefaultMessageWriter(), AsyncChannelOptions{})
+
+	pipesContext, err := NewPipesContext(&types.PipesContextData{}, map[string]json.RawMessage{
+		"path": json.RawMessage(`"` + path + `"`),
+	}, writer)
+	require.NoError(t, err)
+	require.IsType(t, &AsyncChannel{}, pipesContext.Channel)
+
+	err = pipesContext.ReportCustomMessage("payload")
+	require.NoError(t, err)
+	err = pipesContext.Close(nil)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var methods []types.Method
+	for _, line := range bytes.Split(bytes.TrimSpace(content), []byte("\n")) {
+		var message types.PipesMessage
+		err = json.Unmarshal(line, &message)
+		require.NoError(t, err)
+		methods = append(methods, message.Method)
+	}
+	require.Equal(t, []types.Method{types.Opened, types.ReportCustomMessage, types.Closed}, methods)
+}
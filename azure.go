@@ -0,0 +1,100 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// AzureBlobClient is the subset of an Azure Blob Storage client used by the
+// Azure message writer and context loader.
+//
+// The package does not depend on the Azure SDK. Wrap an azblob.Client of
+// github.com/Azure/azure-sdk-for-go/sdk/storage/azblob to satisfy it:
+//
+//	type azureBlobClient struct{ client *azblob.Client }
+//
+//	func (c azureBlobClient) DownloadBlob(ctx context.Context, container, blob string) (io.ReadCloser, error) {
+//	    resp, err := c.client.DownloadStream(ctx, container, blob, nil)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return resp.Body, nil
+//	}
+//
+//	func (c azureBlobClient) UploadBlob(ctx context.Context, container, blob string, data []byte) error {
+//	    _, err := c.client.UploadBuffer(ctx, container, blob, data, nil)
+//	    return err
+//	}
+//
+// Creating that client for an Azurite endpoint makes it work against a local
+// emulator.
+type AzureBlobClient interface {
+	DownloadBlob(ctx context.Context, container, blob string) (io.ReadCloser, error)
+	UploadBlob(ctx context.Context, container, blob string, data []byte) error
+}
+
+// NewAzureBlobStorageMessageWriter creates a MessageWriter that uploads
+// message chunks to Azure Blob Storage. Like the Python writer, it reads the
+// container from the "bucket" message param and the blob name prefix from
+// "key_prefix", and stores chunks as "<key_prefix>/<index>.json".
+func NewAzureBlobStorageMessageWriter(client AzureBlobClient) *BlobStoreMessageWriter {
+	return NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		container, err := payloadParam(params, "bucket")
+		if err != nil {
+			return nil, err
+		}
+		keyPrefix, err := payloadParam(params, "key_prefix")
+		if err != nil {
+			return nil, err
+		}
+		return &azureChunkUploader{client: client, container: container, keyPrefix: keyPrefix}, nil
+	})
+}
+
+type azureChunkUploader struct {
+	client    AzureBlobClient
+	container string
+	keyPrefix string
+}
+
+func (uploader *azureChunkUploader) UploadChunk(ctx context.Context, index int, chunk []byte) error {
+	return uploader.client.UploadBlob(ctx, uploader.container, chunkKey(uploader.keyPrefix, index), chunk)
+}
+
+// AzureBlobStorageContextLoader loads the context data from the blob named by
+// the "bucket" (container) and "key" context params, as written by
+// PipesAzureBlobStorageContextInjector.
+type AzureBlobStorageContextLoader struct {
+	Client AzureBlobClient
+}
+
+func NewAzureBlobStorageContextLoader(client AzureBlobClient) *AzureBlobStorageContextLoader {
+	return &AzureBlobStorageContextLoader{Client: client}
+}
+
+func (loader *AzureBlobStorageContextLoader) LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	return loader.LoadContextContext(context.Background(), params)
+}
+
+// LoadContextContext is like LoadContext but gives up when ctx is done.
+func (loader *AzureBlobStorageContextLoader) LoadContextContext(ctx context.Context, params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	container, err := payloadParam(params, "bucket")
+	if err != nil {
+		return nil, err
+	}
+	key, err := payloadParam(params, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := loader.Client.DownloadBlob(ctx, container, key)
+	if err != nil {
+		return nil, &PayloadError{Kind: PayloadErrorKind_Unreadable, Err: err}
+	}
+	defer r.Close()
+
+	return decodeContextData(r)
+}
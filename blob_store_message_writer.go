@@ -0,0 +1,211 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// DefaultBlobStoreInterval is how often a BlobStoreChannel uploads buffered
+// messages when neither the writer nor the message params set an interval.
+const DefaultBlobStoreInterval = 10 * time.Second
+
+// DefaultBlobStoreUploadTimeout bounds a single chunk upload when the writer
+// does not set an upload timeout.
+const DefaultBlobStoreUploadTimeout = time.Minute
+
+// ChunkUploader uploads chunks of newline-delimited messages to a blob store.
+//
+// Dagster reads chunks in order of their index, which starts at 1, so backends
+// usually store the chunk under a name like "<prefix>/<index>.json".
+//
+// UploadChunk must give up when ctx is done, so that a hung upload cannot
+// keep PipesContext.Close from returning.
+type ChunkUploader interface {
+	UploadChunk(ctx context.Context, index int, chunk []byte) error
+}
+
+// BlobStoreMessageWriter is a MessageWriter that buffers messages and
+// periodically uploads them as numbered chunks through a ChunkUploader.
+//
+// Cloud backends only need to build a ChunkUploader from the message params:
+//
+//	writer := dagster_pipes.NewBlobStoreMessageWriter(
+//	    func(params map[string]json.RawMessage) (dagster_pipes.ChunkUploader, error) {
+//	        return newMyUploader(params)
+//	    },
+//	)
+type BlobStoreMessageWriter struct {
+	// NewUploader creates the uploader for a session from the message params.
+	NewUploader func(params map[string]json.RawMessage) (ChunkUploader, error)
+	// Interval between uploads. It is overridden by the "interval" message
+	// param, in seconds, and defaults to DefaultBlobStoreInterval.
+	Interval time.Duration
+	// UploadTimeout bounds every chunk upload, including the last one made
+	// when the channel is closed. It defaults to
+	// DefaultBlobStoreUploadTimeout.
+	UploadTimeout time.Duration
+}
+
+// NewBlobStoreMessageWriter creates a BlobStoreMessageWriter that uploads
+// chunks with the uploader returned by newUploader.
+func NewBlobStoreMessageWriter(newUploader func(params map[string]json.RawMessage) (ChunkUploader, error)) *BlobStoreMessageWriter {
+	return &BlobStoreMessageWriter{NewUploader: newUploader}
+}
+
+func (writer *BlobStoreMessageWriter) Open(params map[string]json.RawMessage) (MessageWriterChannel, error) {
+	uploader, err := writer.NewUploader(params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create chunk uploader: %w", err)
+	}
+
+	interval := writer.Interval
+	if value, ok := params["interval"]; ok {
+		var seconds float64
+		if err := json.Unmarshal(value, &seconds); err != nil {
+			return nil, &PayloadError{Kind: PayloadErrorKind_Malformed, Err: fmt.Errorf("cannot unmarshal interval: %w", err)}
+		}
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+	return newBlobStoreChannel(uploader, interval, writer.UploadTimeout), nil
+}
+
+func (writer *BlobStoreMessageWriter) GetOpenedPayload() map[string]any {
+	return map[string]any{
+		"extras": writer.GetOpenedExtras(),
+	}
+}
+
+func (writer *BlobStoreMessageWriter) GetOpenedExtras() map[string]any {
+	return make(map[string]any)
+}
+
+// BlobStoreChannel buffers messages in memory and uploads them as a new chunk
+// on every interval tick. Remaining messages are uploaded when the channel
+// is closed.
+//
+// A failed upload keeps the messages and the chunk index, and is retried on
+// the next tick or on Close, so that a transient failure leaves no gap in the
+// chunks Dagster reads.
+type BlobStoreChannel struct {
+	uploader      ChunkUploader
+	uploadTimeout time.Duration
+
+	mu     sync.Mutex
+	buffer []byte
+
+	uploadMu sync.Mutex
+	index    int
+
+	// ctx is cancelled by Close to abort a periodic upload in progress; its
+	// messages are uploaded again by the last upload.
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBlobStoreChannel creates a BlobStoreChannel and starts uploading chunks
+// every interval. A non-positive interval means DefaultBlobStoreInterval.
+// Uploads time out after DefaultBlobStoreUploadTimeout.
+func NewBlobStoreChannel(uploader ChunkUploader, interval time.Duration) *BlobStoreChannel {
+	return newBlobStoreChannel(uploader, interval, 0)
+}
+
+func newBlobStoreChannel(uploader ChunkUploader, interval, uploadTimeout time.Duration) *BlobStoreChannel {
+	if interval <= 0 {
+		interval = DefaultBlobStoreInterval
+	}
+	if uploadTimeout <= 0 {
+		uploadTimeout = DefaultBlobStoreUploadTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := &BlobStoreChannel{
+		uploader:      uploader,
+		uploadTimeout: uploadTimeout,
+		ctx:           ctx,
+		cancel:        cancel,
+		stopped:       make(chan struct{}),
+	}
+	go channel.uploadLoop(interval)
+	return channel
+}
+
+func (channel *BlobStoreChannel) Write(message *types.PipesMessage) error {
+	line, err := encodeMessageLine(message)
+	if err != nil {
+		return err
+	}
+
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	channel.buffer = append(channel.buffer, line...)
+	return nil
+}
+
+// Flush uploads the buffered messages as a new chunk. Nothing is uploaded
+// when the buffer is empty. The messages are only dropped from the buffer
+// once the upload succeeded.
+func (channel *BlobStoreChannel) Flush() error {
+	return channel.upload(context.Background())
+}
+
+// upload uploads the buffered messages, giving up when ctx is done or the
+// upload timeout expires.
+func (channel *BlobStoreChannel) upload(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, channel.uploadTimeout)
+	defer cancel()
+
+	channel.uploadMu.Lock()
+	defer channel.uploadMu.Unlock()
+
+	channel.mu.Lock()
+	chunk := channel.buffer[:len(channel.buffer):len(channel.buffer)]
+	channel.mu.Unlock()
+
+	if len(chunk) == 0 {
+		return nil
+	}
+	if err := channel.uploader.UploadChunk(ctx, channel.index+1, chunk); err != nil {
+		return err
+	}
+	channel.index++
+
+	channel.mu.Lock()
+	channel.buffer = channel.buffer[len(chunk):]
+	channel.mu.Unlock()
+	return nil
+}
+
+// Close stops the periodic uploads, aborting one in progress, and uploads
+// the remaining messages, including those of earlier failed uploads. That
+// last upload is bounded by the upload timeout, and its error is returned.
+func (channel *BlobStoreChannel) Close() error {
+	channel.closeOnce.Do(func() {
+		channel.cancel()
+		<-channel.stopped
+	})
+	return channel.Flush()
+}
+
+func (channel *BlobStoreChannel) uploadLoop(interval time.Duration) {
+	defer close(channel.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// A failed upload is retried on the next tick, and its error is
+			// returned by Close if it keeps failing.
+			channel.upload(channel.ctx)
+		case <-channel.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+type memoryUploader struct {
+	mu     sync.Mutex
+	chunks map[int][]byte
+	err    error
+}
+
+func (uploader *memoryUploader) UploadChunk(_ context.Context, index int, chunk []byte) error {
+	uploader.mu.Lock()
+	defer uploader.mu.Unlock()
+	if uploader.err != nil {
+		return uploader.err
+	}
+	if uploader.chunks == nil {
+		uploader.chunks = make(map[int][]byte)
+	}
+	uploader.chunks[index] = chunk
+	return nil
+}
+
+func (uploader *memoryUploader) chunkCount() int {
+	uploader.mu.Lock()
+	defer uploader.mu.Unlock()
+	return len(uploader.chunks)
+}
+
+func TestBlobStoreChannel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uploads chunks periodically", func(t *testing.T) {
+		t.Parallel()
+		uploader := &memoryUploader{}
+		channel := NewBlobStoreChannel(uploader, 10*time.Millisecond)
+
+		err := channel.Write(types.NewMessage(types.Opened, nil))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return uploader.chunkCount() == 1 }, time.Second, 5*time.Millisecond)
+
+		err = channel.Write(types.NewMessage(types.Closed, nil))
+		require.NoError(t, err)
+		err = channel.Close()
+		require.NoError(t, err)
+
+		require.Len(t, uploader.chunks, 2)
+		var message types.PipesMessage
+		err = json.Unmarshal(bytes.TrimSpace(uploader.chunks[2]), &message)
+		require.NoError(t, err)
+		require.Equal(t, types.Closed, message.Method)
+	})
+
+	t.Run("uploads remaining messages on close", func(t *testing.T) {
+		t.Parallel()
+		uploader := &memoryUploader{}
+		channel := NewBlobStoreChannel(uploader, time.Hour)
+
+		for range 3 {
+			err := channel.Write(types.NewMessage(types.ReportCustomMessage, nil))
+			require.NoError(t, err)
+		}
+		require.Zero(t, uploader.chunkCount())
+
+		err := channel.Close()
+		require.NoError(t, err)
+		require.Len(t, uploader.chunks, 1)
+		require.Len(t, bytes.Split(bytes.TrimSpace(uploader.chunks[1]), []byte("\n")), 3)
+	})
+
+	t.Run("retries failed uploads", func(t *testing.T) {
+		t.Parallel()
+		uploader := &memoryUploader{err: errors.New("transient")}
+		channel := NewBlobStoreChannel(uploader, time.Hour)
+
+		err := channel.Write(types.NewMessage(types.Opened, nil))
+		require.NoError(t, err)
+		require.Error(t, channel.Flush())
+
+		uploader.mu.Lock()
+		uploader.err = nil
+		uploader.mu.Unlock()
+
+		err = channel.Write(types.NewMessage(types.Closed, nil))
+		require.NoError(t, err)
+		require.NoError(t, channel.Close())
+
+		require.Len(t, uploader.chunks, 1)
+		lines := bytes.Split(bytes.TrimSpace(uploader.chunks[1]), []byte("\n"))
+		require.Len(t, lines, 2)
+	})
+
+	t.Run("reports upload errors on close", func(t *testing.T) {
+		t.Parallel()
+		uploadErr := errors.New("upload failed")
+		uploader := &memoryUploader{err: uploadErr}
+		channel := NewBlobStoreChannel(uploader, time.Hour)
+
+		err := channel.Write(types.NewMessage(types.Opened, nil))
+		require.NoError(t, err)
+		err = channel.Close()
+		require.ErrorIs(t, err, uploadErr)
+	})
+}
+
+// hungUploader blocks every upload until its context is done.
+type hungUploader struct{}
+
+func (hungUploader) UploadChunk(ctx context.Context, _ int, _ []byte) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBlobStoreChannel_UploadTimeout(t *testing.T) {
+	t.Parallel()
+	channel := newBlobStoreChannel(hungUploader{}, time.Millisecond, 50*time.Millisecond)
+
+	err := channel.Write(types.NewMessage(types.Opened, nil))
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = channel.Close()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestBlobStoreMessageWriter(t *testing.T) {
+	t.Parallel()
+	uploader := &memoryUploader{}
+
+	var received map[string]json.RawMessage
+	writer := NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		received = params
+		return uploader, nil
+	})
+
+	params := map[string]json.RawMessage{
+		"key_prefix": json.RawMessage(`"prefix"`),
+		"interval":   json.RawMessage(`0.01`),
+	}
+	channel, err := writer.Open(params)
+	require.NoError(t, err)
+	require.Equal(t, params, received)
+
+	err = channel.Write(types.NewMessage(types.Opened, nil))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return uploader.chunkCount() == 1 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, channel.(*BlobStoreChannel).Close())
+}
+
+func TestBlobStoreMessageWriter_OpenErrors(t *testing.T) {
+	t.Parallel()
+	uploaderErr := errors.New("missing bucket")
+	writer := NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		if _, ok := params["bucket"]; !ok {
+			return nil, uploaderErr
+		}
+		return &memoryUploader{}, nil
+	})
+
+	_, err := writer.Open(nil)
+	require.ErrorIs(t, err, uploaderErr)
+
+	_, err = writer.Open(map[string]json.RawMessage{
+		"bucket":   json.RawMessage(`"my-bucket"`),
+		"interval": json.RawMessage(`"soon"`),
+	})
+	require.ErrorIs(t, err, PayloadErrorKind_Malformed)
+}
@@ -0,0 +1,196 @@
+// Package client launches external processes that speak Dagster Pipes and
+// collects what they report, playing the role of the Dagster orchestration
+// process.
+//
+// It is useful to orchestrate Go programs from Go, and to test programs that
+// use dagster_pipes end to end without Dagster:
+//
+//	cmd := exec.CommandContext(ctx, "./my-binary")
+//	result, err := client.NewClient().Run(cmd, &types.PipesContextData{
+//	    AssetKeys: []string{"my_asset"},
+//	    RunID:     "local",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, materialization := range result.Materializations() {
+//	    log.Println("materialized", materialization.AssetKey)
+//	}
+package client
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	dagster_pipes "github.com/wingyplus/dagster-pipes-go"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// Client launches pipes processes.
+type Client struct {
+	// ContextInjector passes the context data to the process. It defaults to
+	// a TempFileContextInjector.
+	ContextInjector dagster_pipes.ContextInjector
+	// MessageReader receives the messages of the process. It defaults to a
+	// FileMessageReader.
+	MessageReader MessageReader
+	// OnEvent, if set, is called with every event as it is received.
+	OnEvent func(Event)
+}
+
+// NewClient returns a Client that passes the context in a temporary file
+// and reads messages from another one.
+func NewClient() *Client {
+	return &Client{
+		ContextInjector: dagster_pipes.NewTempFileContextInjector(),
+		MessageReader:   NewFileMessageReader(),
+	}
+}
+
+// Result summarizes a run of a pipes process.
+type Result struct {
+	// ExitCode is the exit status of the process, or -1 when it was
+	// terminated by a signal.
+	ExitCode int
+	// Events are the events the process reported, in order.
+	Events []Event
+}
+
+// Materializations returns the asset materializations reported by the
+// process.
+func (result *Result) Materializations() []*AssetMaterializationEvent {
+	return eventsOf[*AssetMaterializationEvent](result.Events)
+}
+
+// AssetChecks returns the asset check results reported by the process.
+func (result *Result) AssetChecks() []*AssetCheckEvent {
+	return eventsOf[*AssetCheckEvent](result.Events)
+}
+
+// Logs returns the log messages sent by the process.
+func (result *Result) Logs() []*LogEvent {
+	return eventsOf[*LogEvent](result.Events)
+}
+
+// CustomMessages returns the custom messages sent by the process.
+func (result *Result) CustomMessages() []*CustomMessageEvent {
+	return eventsOf[*CustomMessageEvent](result.Events)
+}
+
+// Closed reports whether the process closed the session.
+func (result *Result) Closed() bool {
+	return len(eventsOf[*ClosedEvent](result.Events)) > 0
+}
+
+// Exception returns the exception the process closed the session with, or
+// nil when it reported none.
+func (result *Result) Exception() *types.PipesException {
+	for _, closed := range eventsOf[*ClosedEvent](result.Events) {
+		if closed.Exception != nil {
+			return closed.Exception
+		}
+	}
+	return nil
+}
+
+// Success reports whether the process exited with status 0, closed the
+// session and reported no exception.
+func (result *Result) Success() bool {
+	return result.ExitCode == 0 && result.Closed() && result.Exception() == nil
+}
+
+func eventsOf[E Event](events []Event) []E {
+	var matched []E
+	for _, event := range events {
+		if e, ok := event.(E); ok {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// Run passes data to cmd, runs it and waits for it to exit, collecting the
+// events it reports. Use exec.CommandContext to bound the run.
+//
+// cmd runs with its own Env, or the environment of the current process when
+// Env is nil, plus the pipes variables.
+//
+// A non-zero exit status is not an error; check Result.ExitCode or
+// Result.Success. Messages that cannot be decoded are skipped, and the
+// returned error lists them along with the result.
+func (client *Client) Run(cmd *exec.Cmd, data *types.PipesContextData) (*Result, error) {
+	injector := client.ContextInjector
+	if injector == nil {
+		injector = dagster_pipes.NewTempFileContextInjector()
+	}
+	reader := client.MessageReader
+	if reader == nil {
+		reader = NewFileMessageReader()
+	}
+
+	contextParams, cleanup, err := injector.InjectContext(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.run(cmd, reader, contextParams)
+	return result, errors.Join(err, cleanup())
+}
+
+func (client *Client) run(cmd *exec.Cmd, reader MessageReader, contextParams map[string]any) (*Result, error) {
+	messageParams, read, err := reader.Open(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := dagster_pipes.ContextEnv(contextParams, messageParams)
+	if err != nil {
+		return nil, errors.Join(err, read(closedChan(), discard))
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...)
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Join(err, read(closedChan(), discard))
+	}
+
+	exited := make(chan struct{})
+	var waitErr error
+	go func() {
+		waitErr = cmd.Wait()
+		close(exited)
+	}()
+
+	result := &Result{}
+	var errs []error
+	readErr := read(exited, func(message *types.PipesMessage) {
+		event, err := DecodeEvent(message)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		result.Events = append(result.Events, event)
+		if client.OnEvent != nil {
+			client.OnEvent(event)
+		}
+	})
+	errs = append(errs, readErr)
+	<-exited
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		errs = append(errs, waitErr)
+	}
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	return result, errors.Join(errs...)
+}
+
+func closedChan() <-chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+func discard(*types.PipesMessage) {}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dagster_pipes "github.com/wingyplus/dagster-pipes-go"
+	"github.com/wingyplus/dagster-pipes-go/metadata"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+const helperProcessEnvVar = "GO_WANT_HELPER_PROCESS"
+
+// TestHelperProcess is not a real test. It is the pipes process launched by
+// the other tests, which re-execute the test binary.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(helperProcessEnvVar)
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	os.Exit(runHelperProcess(mode))
+}
+
+// runHelperProcess plays the pipes process selected by mode and returns its
+// exit status.
+func runHelperProcess(mode string) int {
+	context, err := dagster_pipes.OpenDasterPipes()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	fmt.Println("not a message")
+	context.Log().Info("processing " + context.Data.RunID)
+	if err := context.ReportAssetMaterialization("my_asset", dagster_pipes.Metadata{
+		"row_count": metadata.FromInt(1000),
+	}, "v1"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	severity := types.Warn
+	if err := context.ReportAssetCheck("row_count_check", true, "my_asset", &severity, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch mode {
+	case "fail":
+		context.Close(dagster_pipes.PipesExceptionError(errors.New("boom")))
+		return 1
+	case "exit":
+		// Exit without closing the session.
+		return 3
+	default:
+		if err := context.Close(nil); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		return 0
+	}
+}
+
+func helperCommand(t *testing.T, mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperProcessEnvVar+"="+mode)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+func TestClient_Run(t *testing.T) {
+	t.Parallel()
+	data := &types.PipesContextData{
+		AssetKeys: []string{"my_asset"},
+		Extras:    map[string]any{},
+		RunID:     "012345",
+	}
+
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		var received []types.Method
+		client := &Client{
+			ContextInjector: &dagster_pipes.TempFileContextInjector{Dir: t.TempDir()},
+			MessageReader:   &FileMessageReader{Dir: t.TempDir()},
+			OnEvent:         func(event Event) { received = append(received, event.Method()) },
+		}
+
+		result, err := client.Run(helperCommand(t, "success"), data)
+		require.NoError(t, err)
+		require.True(t, result.Success())
+		require.Equal(t, []types.Method{
+			types.Opened,
+			types.Log,
+			types.ReportAssetMaterialization,
+			types.ReportAssetCheck,
+			types.Closed,
+		}, received)
+
+		require.Equal(t, []*LogEvent{{Message: "processing 012345", Level: "INFO"}}, result.Logs())
+		materializations := result.Materializations()
+		require.Len(t, materializations, 1)
+		require.Equal(t, "my_asset", materializations[0].AssetKey)
+		require.Equal(t, "v1", *materializations[0].DataVersion)
+		require.Contains(t, materializations[0].Metadata, "row_count")
+
+		checks := result.AssetChecks()
+		require.Len(t, checks, 1)
+		require.Equal(t, "row_count_check", checks[0].CheckName)
+		require.True(t, checks[0].Passed)
+		require.Equal(t, types.Warn, *checks[0].Severity)
+	})
+
+	t.Run("stdio", func(t *testing.T) {
+		t.Parallel()
+		var output bytes.Buffer
+		client := &Client{
+			ContextInjector: dagster_pipes.NewInlineContextInjector(),
+			MessageReader:   NewStdioMessageReader("stdout", &output),
+		}
+
+		result, err := client.Run(helperCommand(t, "success"), data)
+		require.NoError(t, err)
+		require.True(t, result.Success())
+		require.Len(t, result.Materializations(), 1)
+		require.Contains(t, output.String(), "not a message\n")
+	})
+
+	t.Run("exception", func(t *testing.T) {
+		t.Parallel()
+		result, err := NewClient().Run(helperCommand(t, "fail"), data)
+		require.NoError(t, err)
+		require.False(t, result.Success())
+		require.Equal(t, 1, result.ExitCode)
+		require.True(t, result.Closed())
+		require.NotNil(t, result.Exception())
+		require.Equal(t, "boom", *result.Exception().Message)
+	})
+
+	t.Run("exit without closing", func(t *testing.T) {
+		t.Parallel()
+		result, err := NewClient().Run(helperCommand(t, "exit"), data)
+		require.NoError(t, err)
+		require.Equal(t, 3, result.ExitCode)
+		require.False(t, result.Closed())
+		require.Len(t, result.Materializations(), 1)
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewClient().Run(exec.Command("/nonexistent/pipes-process"), data)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// Event is a message received from the external process, decoded by
+// DecodeEvent into one of the event types of this package.
+type Event interface {
+	Method() types.Method
+}
+
+// OpenedEvent is received when the external process opens the session.
+type OpenedEvent struct {
+	Extras map[string]any `json:"extras"`
+}
+
+func (*OpenedEvent) Method() types.Method { return types.Opened }
+
+// ClosedEvent is received when the external process closes the session.
+// Exception is set when the process reported a failure.
+type ClosedEvent struct {
+	Exception *types.PipesException
+}
+
+func (*ClosedEvent) Method() types.Method { return types.Closed }
+
+// LogEvent is a message logged to the Dagster event log.
+type LogEvent struct {
+	Message string `json:"message"`
+	Level   string `json:"level"`
+}
+
+func (*LogEvent) Method() types.Method { return types.Log }
+
+// AssetMaterializationEvent reports that an asset was materialized.
+type AssetMaterializationEvent struct {
+	AssetKey    string                               `json:"asset_key"`
+	Metadata    map[string]*types.PipesMetadataValue `json:"metadata"`
+	DataVersion *string                              `json:"data_version"`
+}
+
+func (*AssetMaterializationEvent) Method() types.Method {
+	return types.ReportAssetMaterialization
+}
+
+// AssetCheckEvent reports the result of an asset check.
+type AssetCheckEvent struct {
+	AssetKey  string                               `json:"asset_key"`
+	CheckName string                               `json:"check_name"`
+	Passed    bool                                 `json:"passed"`
+	Severity  *types.AssetCheckSeverity            `json:"severity"`
+	Metadata  map[string]*types.PipesMetadataValue `json:"metadata"`
+}
+
+func (*AssetCheckEvent) Method() types.Method { return types.ReportAssetCheck }
+
+// CustomMessageEvent carries the payload of a custom message.
+type CustomMessageEvent struct {
+	Payload any `json:"payload"`
+}
+
+func (*CustomMessageEvent) Method() types.Method { return types.ReportCustomMessage }
+
+// ExternalStreamEvent carries output the external process wrote to one of
+// its standard streams.
+type ExternalStreamEvent struct {
+	Stream string `json:"stream"`
+	Text   string `json:"text"`
+}
+
+func (*ExternalStreamEvent) Method() types.Method { return types.LogExternalStream }
+
+// DecodeEvent decodes message into the event type matching its method.
+func DecodeEvent(message *types.PipesMessage) (Event, error) {
+	var event Event
+	switch message.Method {
+	case types.Opened:
+		event = &OpenedEvent{}
+	case types.Closed:
+		closed := &ClosedEvent{}
+		if len(message.Params) > 0 {
+			closed.Exception = &types.PipesException{}
+			if err := decodeParams(message.Params, closed.Exception); err != nil {
+				return nil, fmt.Errorf("cannot decode %s message: %w", message.Method, err)
+			}
+		}
+		return closed, nil
+	case types.Log:
+		event = &LogEvent{}
+	case types.ReportAssetMaterialization:
+		event = &AssetMaterializationEvent{}
+	case types.ReportAssetCheck:
+		event = &AssetCheckEvent{}
+	case types.ReportCustomMessage:
+		event = &CustomMessageEvent{}
+	case types.LogExternalStream:
+		event = &ExternalStreamEvent{}
+	default:
+		return nil, fmt.Errorf("unknown message method %q", message.Method)
+	}
+	if err := decodeParams(message.Params, event); err != nil {
+		return nil, fmt.Errorf("cannot decode %s message: %w", message.Method, err)
+	}
+	return event, nil
+}
+
+// decodeParams decodes the params of a message into v through their JSON
+// encoding.
+func decodeParams(params map[string]any, v any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/internal/helper"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message *types.PipesMessage
+		event   Event
+	}{
+		{
+			"opened",
+			types.NewMessage(types.Opened, map[string]any{"extras": map[string]any{}}),
+			&OpenedEvent{Extras: map[string]any{}},
+		},
+		{
+			"closed",
+			types.NewMessage(types.Closed, nil),
+			&ClosedEvent{},
+		},
+		{
+			"closed with exception",
+			types.NewMessage(types.Closed, map[string]any{"name": "error", "message": "boom"}),
+			&ClosedEvent{Exception: &types.PipesException{Name: helper.Ptr("error"), Message: helper.Ptr("boom")}},
+		},
+		{
+			"custom message",
+			types.NewMessage(types.ReportCustomMessage, map[string]any{"payload": map[string]any{"count": 1.0}}),
+			&CustomMessageEvent{Payload: map[string]any{"count": 1.0}},
+		},
+		{
+			"external stream",
+			types.NewMessage(types.LogExternalStream, map[string]any{"stream": "stdout", "text": "hello\n", "extras": map[string]any{}}),
+			&ExternalStreamEvent{Stream: "stdout", Text: "hello\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			event, err := DecodeEvent(tt.message)
+			require.NoError(t, err)
+			require.Equal(t, tt.event, event)
+			require.Equal(t, tt.message.Method, event.Method())
+		})
+	}
+
+	t.Run("unknown method", func(t *testing.T) {
+		t.Parallel()
+		_, err := DecodeEvent(types.NewMessage("report_unknown", nil))
+		require.ErrorContains(t, err, "report_unknown")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		t.Parallel()
+		_, err := DecodeEvent(types.NewMessage(types.Log, map[string]any{"message": 1}))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// DefaultPollInterval is how often a FileMessageReader checks the message
+// file for new messages.
+const DefaultPollInterval = 100 * time.Millisecond
+
+// maxMessageSize bounds the size of a single message line.
+const maxMessageSize = 16 << 20
+
+// MessageReader receives the messages of the external process.
+type MessageReader interface {
+	// Open prepares cmd, before it starts, to send messages. It returns the
+	// message params to pass to the process, and read, which must be called
+	// exactly once, after the process started or failed to start.
+	Open(cmd *exec.Cmd) (params map[string]any, read ReadFunc, err error)
+}
+
+// ReadFunc decodes messages and calls handle for each of them, in order. It
+// returns once exited is closed and every message has been read, and
+// releases what Open created.
+type ReadFunc func(exited <-chan struct{}, handle func(*types.PipesMessage)) error
+
+// FileMessageReader reads messages from a file that the process appends to,
+// tailing it while the process runs.
+type FileMessageReader struct {
+	// Dir is where the message file is created. An empty Dir means the
+	// default directory for temporary files.
+	Dir string
+	// PollInterval defaults to DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+func NewFileMessageReader() *FileMessageReader {
+	return &FileMessageReader{}
+}
+
+func (reader *FileMessageReader) Open(cmd *exec.Cmd) (map[string]any, ReadFunc, error) {
+	dir, err := os.MkdirTemp(reader.Dir, "dagster-pipes-messages-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	path := filepath.Join(dir, "messages")
+
+	interval := reader.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	read := func(exited <-chan struct{}, handle func(*types.PipesMessage)) error {
+		err := tailFile(path, interval, exited, handle)
+		return errors.Join(err, os.RemoveAll(dir))
+	}
+	return map[string]any{"path": path}, read, nil
+}
+
+// tailFile decodes the messages appended to the file at path until exited
+// is closed. The file may not exist until the process writes to it.
+func tailFile(path string, interval time.Duration, exited <-chan struct{}, handle func(*types.PipesMessage)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		file    *os.File
+		pending []byte
+		errs    []error
+	)
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	// poll reads what was appended since the last poll and decodes the
+	// complete lines.
+	poll := func() error {
+		if file == nil {
+			f, err := os.Open(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			file = f
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, data...)
+		for {
+			line, rest, ok := bytes.Cut(pending, []byte("\n"))
+			if !ok {
+				break
+			}
+			pending = rest
+			errs = append(errs, decodeLine(line, handle))
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := poll(); err != nil {
+				return err
+			}
+		case <-exited:
+			if err := poll(); err != nil {
+				return err
+			}
+			// The process may have exited without ending its last line.
+			errs = append(errs, decodeLine(pending, handle))
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// StdioMessageReader reads messages from the standard output or standard
+// error of the process. Lines that are not messages are copied to Output.
+type StdioMessageReader struct {
+	// Stream is "stdout" or "stderr".
+	Stream string
+	// Output receives the lines of Stream that are not messages. Nil
+	// discards them.
+	Output io.Writer
+}
+
+func NewStdioMessageReader(stream string, output io.Writer) *StdioMessageReader {
+	return &StdioMessageReader{Stream: stream, Output: output}
+}
+
+func (reader *StdioMessageReader) Open(cmd *exec.Cmd) (map[string]any, ReadFunc, error) {
+	if reader.Stream != "stdout" && reader.Stream != "stderr" {
+		return nil, nil, fmt.Errorf("unknown stdio stream %q, expected %q or %q", reader.Stream, "stdout", "stderr")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if reader.Stream == "stdout" {
+		cmd.Stdout = w
+	} else {
+		cmd.Stderr = w
+	}
+
+	read := func(exited <-chan struct{}, handle func(*types.PipesMessage)) error {
+		defer r.Close()
+		// The process holds its own copy of the write end, so the read
+		// end sees EOF once the process and its children are gone.
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return scanStdio(r, reader.Output, handle)
+	}
+	return map[string]any{"stdio": reader.Stream}, read, nil
+}
+
+func scanStdio(r io.Reader, output io.Writer, handle func(*types.PipesMessage)) error {
+	if output == nil {
+		output = io.Discard
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		if message, ok := parseMessage(scanner.Bytes()); ok {
+			handle(message)
+			continue
+		}
+		if _, err := fmt.Fprintln(output, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// decodeLine decodes a line of a message file and passes the message to
+// handle. Blank lines are skipped.
+func decodeLine(line []byte, handle func(*types.PipesMessage)) error {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	message, ok := parseMessage(line)
+	if !ok {
+		return fmt.Errorf("invalid message: %q", line)
+	}
+	handle(message)
+	return nil
+}
+
+// parseMessage decodes line as a message, reporting false when it is not
+// one. Like Dagster, it recognizes messages by the presence of the version
+// field.
+func parseMessage(line []byte) (*types.PipesMessage, bool) {
+	if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+		return nil, false
+	}
+	var message struct {
+		types.PipesMessage
+		// Version tells whether the version field is present at all.
+		Version *string `json:"__dagster_pipes_version"`
+	}
+	if err := json.Unmarshal(line, &message); err != nil {
+		return nil, false
+	}
+	if message.Version == nil || message.Method == "" {
+		return nil, false
+	}
+	message.DagsterPipesVersion = *message.Version
+	return &message.PipesMessage, true
+}
@@ -0,0 +1,241 @@
+package dagster_pipes
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CompositeLoader chains several loaders. Context params and message params
+// are resolved independently: each comes from the first loader, in order,
+// that has it. A loader that has a param but fails to load it stops the
+// search, so that a broken value is never silently replaced by another one.
+//
+// For example, to let an override file take precedence over the
+// environment and the command line:
+//
+//	loader := dagster_pipes.NewCompositeLoader(
+//	    dagster_pipes.NewDotEnvLoader(".env.pipes"),
+//	    dagster_pipes.NewEnvVarLoader(),
+//	    dagster_pipes.NewCLIArgLoader(),
+//	)
+type CompositeLoader struct {
+	Loaders []LoadParams
+
+	mu             sync.Mutex
+	contextOrigin  ParamOrigin
+	messagesOrigin ParamOrigin
+}
+
+// NewCompositeLoader returns a CompositeLoader that tries loaders in order.
+func NewCompositeLoader(loaders ...LoadParams) *CompositeLoader {
+	return &CompositeLoader{Loaders: loaders}
+}
+
+func (loader *CompositeLoader) IsDagsterPipesProcess() bool {
+	for _, l := range loader.Loaders {
+		if l.IsDagsterPipesProcess() {
+			return true
+		}
+	}
+	return false
+}
+
+func (loader *CompositeLoader) LoadContextParams() (map[string]json.RawMessage, error) {
+	params, origin, err := loader.load("context", LoadParams.LoadContextParams)
+	loader.mu.Lock()
+	loader.contextOrigin = origin
+	loader.mu.Unlock()
+	return params, err
+}
+
+func (loader *CompositeLoader) LoadMessageParams() (map[string]json.RawMessage, error) {
+	params, origin, err := loader.load("messages", LoadParams.LoadMessageParams)
+	loader.mu.Lock()
+	loader.messagesOrigin = origin
+	loader.mu.Unlock()
+	return params, err
+}
+
+// ContextOrigin returns the origin of the context params loaded by
+// LoadContextParams, or an empty origin when they have not been loaded.
+func (loader *CompositeLoader) ContextOrigin() ParamOrigin {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	return loader.contextOrigin
+}
+
+// MessagesOrigin returns the origin of the message params loaded by
+// LoadMessageParams, or an empty origin when they have not been loaded.
+func (loader *CompositeLoader) MessagesOrigin() ParamOrigin {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	return loader.messagesOrigin
+}
+
+func (loader *CompositeLoader) load(
+	param string,
+	load func(LoadParams) (map[string]json.RawMessage, error),
+) (map[string]json.RawMessage, ParamOrigin, error) {
+	var tried []*ParamsError
+	for _, l := range loader.Loaders {
+		params, err := load(l)
+		if err == nil {
+			return params, paramOrigin(l), nil
+		}
+		var paramsErr *ParamsError
+		if !errors.As(err, &paramsErr) || paramsErr.Source != ParamsErrorKind_NotPresent {
+			return nil, "", err
+		}
+		tried = append(tried, paramsErr)
+	}
+	return nil, "", &ParamsError{
+		Param:  param,
+		Origin: ParamOrigin_Composite,
+		Source: ParamsErrorKind_NotPresent,
+		Tried:  tried,
+	}
+}
+
+func paramOrigin(loader LoadParams) ParamOrigin {
+	if source, ok := loader.(ParamsSource); ok {
+		return source.Origin()
+	}
+	return ParamOrigin_Unknown
+}
+
+// DotEnvLoader loads params from a dotenv-style file holding the same
+// variables as the environment read by EnvVarLoader:
+//
+//	# .env.pipes
+//	DAGSTER_PIPES_CONTEXT=eJwVwdEJgCAQ...
+//	export DAGSTER_PIPES_MESSAGES="eJwVwdEJgCAQ..."
+//
+// A missing file is treated as a file without any params.
+type DotEnvLoader struct {
+	Path string
+}
+
+// NewDotEnvLoader returns a DotEnvLoader that reads the file at path.
+func NewDotEnvLoader(path string) *DotEnvLoader {
+	return &DotEnvLoader{Path: path}
+}
+
+func (loader *DotEnvLoader) Origin() ParamOrigin {
+	return ParamOrigin_DotEnv
+}
+
+func (loader *DotEnvLoader) IsDagsterPipesProcess() bool {
+	_, ok, err := loader.lookup(DAGSTER_PIPES_CONTEXT_ENV_VAR)
+	return err == nil && ok
+}
+
+func (loader *DotEnvLoader) LoadContextParams() (map[string]json.RawMessage, error) {
+	return loader.load(DAGSTER_PIPES_CONTEXT_ENV_VAR)
+}
+
+func (loader *DotEnvLoader) LoadMessageParams() (map[string]json.RawMessage, error) {
+	return loader.load(DAGSTER_PIPES_MESSAGES_ENV_VAR)
+}
+
+func (loader *DotEnvLoader) load(name string) (map[string]json.RawMessage, error) {
+	param, ok, err := loader.lookup(name)
+	if err != nil {
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_Invalid,
+			Param:  name,
+			Origin: ParamOrigin_DotEnv,
+			Err:    err,
+		}
+	}
+	if !ok {
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_NotPresent,
+			Param:  name,
+			Origin: ParamOrigin_DotEnv,
+		}
+	}
+	return decodeParam(param, name, ParamOrigin_DotEnv)
+}
+
+func (loader *DotEnvLoader) lookup(name string) (string, bool, error) {
+	file, err := os.Open(loader.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Encoded params can be longer than the default token size.
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		if !ok {
+			return "", false, fmt.Errorf("%s:%d: expected KEY=VALUE", loader.Path, line)
+		}
+		if strings.TrimSpace(key) == name {
+			return unquoteDotEnvValue(strings.TrimSpace(value)), true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+func unquoteDotEnvValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// StaticLoader returns params set in process, typically to override the
+// params Dagster passed while developing or testing. A nil map means the
+// params are not present.
+type StaticLoader struct {
+	Context  map[string]json.RawMessage
+	Messages map[string]json.RawMessage
+}
+
+// NewStaticLoader returns a StaticLoader for the given context and message
+// params.
+func NewStaticLoader(context, messages map[string]json.RawMessage) *StaticLoader {
+	return &StaticLoader{Context: context, Messages: messages}
+}
+
+func (loader *StaticLoader) Origin() ParamOrigin {
+	return ParamOrigin_Static
+}
+
+func (loader *StaticLoader) IsDagsterPipesProcess() bool {
+	return loader.Context != nil
+}
+
+func (loader *StaticLoader) LoadContextParams() (map[string]json.RawMessage, error) {
+	return loader.load("context", loader.Context)
+}
+
+func (loader *StaticLoader) LoadMessageParams() (map[string]json.RawMessage, error) {
+	return loader.load("messages", loader.Messages)
+}
+
+func (loader *StaticLoader) load(param string, params map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	if params == nil {
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_NotPresent,
+			Param:  param,
+			Origin: ParamOrigin_Static,
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,99 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const encodedParams = "eJwVwdEJgCAQANBV4ha4SDNsjhYQNf0wFTtEjXaP3nsgK/KwT4BVFTxTMLbc2DakIFwfUS516MJWkrw5En3+uYwH0pWDZzpyW1GnSLYRvB9CZRtp"
+
+func TestCompositeLoader(t *testing.T) {
+	t.Parallel()
+	decoded, err := DecodeEnvVar(encodedParams)
+	require.NoError(t, err)
+	override := map[string]json.RawMessage{"path": json.RawMessage(`"/tmp/messages"`)}
+
+	loader := NewCompositeLoader(
+		NewStaticLoader(nil, override),
+		&CLIArgLoader{Args: []string{DAGSTER_PIPES_CONTEXT_CLI_ARG, encodedParams}},
+	)
+	require.True(t, loader.IsDagsterPipesProcess())
+
+	result, err := loader.LoadContextParams()
+	require.NoError(t, err)
+	require.Equal(t, decoded, result)
+	require.Equal(t, ParamOrigin_CLI, loader.ContextOrigin())
+
+	result, err = loader.LoadMessageParams()
+	require.NoError(t, err)
+	require.Equal(t, override, result)
+	require.Equal(t, ParamOrigin_Static, loader.MessagesOrigin())
+}
+
+func TestCompositeLoader_NotPresent(t *testing.T) {
+	t.Parallel()
+	loader := NewCompositeLoader(
+		&CLIArgLoader{},
+		NewDotEnvLoader(filepath.Join(t.TempDir(), "missing.env")),
+	)
+	require.False(t, loader.IsDagsterPipesProcess())
+
+	_, err := loader.LoadContextParams()
+	var paramsErr *ParamsError
+	require.ErrorAs(t, err, &paramsErr)
+	require.Equal(t, ParamsErrorKind_NotPresent, paramsErr.Source)
+	require.Equal(t, []*ParamsError{
+		{Param: DAGSTER_PIPES_CONTEXT_CLI_ARG, Origin: ParamOrigin_CLI, Source: ParamsErrorKind_NotPresent},
+		{Param: DAGSTER_PIPES_CONTEXT_ENV_VAR, Origin: ParamOrigin_DotEnv, Source: ParamsErrorKind_NotPresent},
+	}, paramsErr.Tried)
+	require.ErrorContains(t, err, "origin: cli")
+	require.ErrorContains(t, err, "origin: dotenv file")
+	require.Empty(t, loader.ContextOrigin())
+}
+
+func TestCompositeLoader_Invalid(t *testing.T) {
+	t.Parallel()
+	loader := NewCompositeLoader(
+		&CLIArgLoader{Args: []string{DAGSTER_PIPES_CONTEXT_CLI_ARG + "=not-base64!"}},
+		NewStaticLoader(map[string]json.RawMessage{}, nil),
+	)
+
+	_, err := loader.LoadContextParams()
+	require.ErrorIs(t, err, ParamsErrorKind_Invalid)
+}
+
+func TestDotEnvLoader(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# pipes params\n\nOTHER=1\n" +
+		DAGSTER_PIPES_CONTEXT_ENV_VAR + "=" + encodedParams + "\n" +
+		"export " + DAGSTER_PIPES_MESSAGES_ENV_VAR + "=\"" + encodedParams + "\"\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	decoded, err := DecodeEnvVar(encodedParams)
+	require.NoError(t, err)
+
+	loader := NewDotEnvLoader(path)
+	require.True(t, loader.IsDagsterPipesProcess())
+
+	result, err := loader.LoadContextParams()
+	require.NoError(t, err)
+	require.Equal(t, decoded, result)
+
+	result, err = loader.LoadMessageParams()
+	require.NoError(t, err)
+	require.Equal(t, decoded, result)
+}
+
+func TestDotEnvLoader_Malformed(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("OTHER=1\nbroken\n"), 0o644))
+
+	_, err := NewDotEnvLoader(path).LoadContextParams()
+	require.ErrorContains(t, err, ".env:2")
+}
@@ -0,0 +1,172 @@
+package dagster_pipes
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// ContextWriterChannel is implemented by channels whose writes can wait, such
+// as AsyncChannel with BackpressureBlock. The context-aware report methods
+// use WriteContext so that the wait ends when ctx is done.
+type ContextWriterChannel interface {
+	WriteContext(ctx context.Context, message *types.PipesMessage) error
+}
+
+// session carries the context.Context of a pipes session and signals its end.
+type session struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	done    chan struct{}
+	endOnce sync.Once
+}
+
+func newSession(pipesContext *PipesContext) *session {
+	ctx, cancel := context.WithCancelCause(NewContext(context.Background(), pipesContext))
+	return &session{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+}
+
+// end cancels the session context with cause and marks the session as ended.
+func (s *session) end(cause error) {
+	s.endOnce.Do(func() {
+		s.cancel(cause)
+		close(s.done)
+	})
+}
+
+func (context *PipesContext) getSession() *session {
+	context.sessionOnce.Do(func() {
+		context.session = newSession(context)
+	})
+	return context.session
+}
+
+// Context returns the context.Context of the pipes session. It carries the
+// PipesContext, so FromContext works on it and on every context derived
+// from it.
+//
+// It is cancelled when the session is closed, or when a signal handled by
+// HandleSignals arrives; context.Cause then tells which one happened. Pass it
+// to long-running work so that the work stops when Dagster cancels the run:
+//
+//	rows, err := db.QueryContext(context.Context(), query)
+func (context *PipesContext) Context() context.Context {
+	return context.getSession().ctx
+}
+
+type pipesContextKey struct{}
+
+// NewContext returns a copy of ctx that carries pipesContext. Library code
+// deep in the call stack can then report to Dagster without the
+// PipesContext being passed down explicitly.
+func NewContext(ctx context.Context, pipesContext *PipesContext) context.Context {
+	return context.WithValue(ctx, pipesContextKey{}, pipesContext)
+}
+
+// FromContext returns the PipesContext carried by ctx, if any.
+//
+//	if pipesContext, ok := dagster_pipes.FromContext(ctx); ok {
+//	    pipesContext.Log().Info("halfway there")
+//	}
+func FromContext(ctx context.Context) (*PipesContext, bool) {
+	pipesContext, ok := ctx.Value(pipesContextKey{}).(*PipesContext)
+	return pipesContext, ok
+}
+
+// WithExtrasTimeout derives a context from parent that times out after the
+// duration stored under key in Data.Extras. The value is either a number of
+// seconds or a duration string such as "1h30m". Without such a value, the
+// returned context only ends with parent.
+//
+//	ctx, cancel, err := context.WithExtrasTimeout(context.Context(), "timeout")
+//	if err != nil {
+//	    return err
+//	}
+//	defer cancel()
+func (context *PipesContext) WithExtrasTimeout(parent context.Context, key string) (context.Context, context.CancelFunc, error) {
+	return withExtrasTimeout(parent, context.Data.Extras, key)
+}
+
+func withExtrasTimeout(parent context.Context, extras map[string]any, key string) (context.Context, context.CancelFunc, error) {
+	timeout, ok, err := extrasTimeout(extras, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		ctx, cancel := context.WithCancel(parent)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return ctx, cancel, nil
+}
+
+// ReportAssetMaterializationContext is like ReportAssetMaterialization but
+// gives up when ctx is done.
+func (context *PipesContext) ReportAssetMaterializationContext(
+	ctx context.Context,
+	assetKey string,
+	metadata Metadata,
+	dataVersion string,
+) error {
+	message, err := context.assetMaterializationMessage(assetKey, metadata, dataVersion)
+	if err != nil {
+		return err
+	}
+	return context.writeContext(ctx, message)
+}
+
+// ReportAssetCheckContext is like ReportAssetCheck but gives up when ctx is
+// done.
+func (context *PipesContext) ReportAssetCheckContext(
+	ctx context.Context,
+	checkName string,
+	passed bool,
+	assetKey string,
+	severity *types.AssetCheckSeverity,
+	metadata Metadata,
+) error {
+	message, err := context.assetCheckMessage(checkName, passed, assetKey, severity, metadata)
+	if err != nil {
+		return err
+	}
+	return context.writeContext(ctx, message)
+}
+
+// ReportCustomMessageContext is like ReportCustomMessage but gives up when
+// ctx is done.
+func (context *PipesContext) ReportCustomMessageContext(ctx context.Context, payload any) error {
+	return context.writeContext(ctx, customMessage(payload))
+}
+
+// writeContext sends message through the channel unless ctx is done or the
+// context is closed.
+func (context *PipesContext) writeContext(ctx context.Context, message *types.PipesMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	write := context.Channel.Write
+	if channel, ok := context.Channel.(ContextWriterChannel); ok {
+		write = func(message *types.PipesMessage) error {
+			return channel.WriteContext(ctx, message)
+		}
+	}
+	return context.send(message, write)
+}
+
+// extrasTimeout reads a timeout from extras. It reports false when key is
+// not set.
+func extrasTimeout(extras map[string]any, key string) (time.Duration, bool, error) {
+	value, ok := extras[key]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+	var timeout time.Duration
+	if err := decodeExtra(value, reflect.ValueOf(&timeout).Elem()); err != nil {
+		return 0, false, &ExtraError{Key: key, Err: err}
+	}
+	return timeout, true, nil
+}
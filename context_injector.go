@@ -0,0 +1,93 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// ContextInjector passes context data to a child process. It is the
+// counterpart of LoadContext, for Go programs that launch other
+// pipes-enabled processes.
+type ContextInjector interface {
+	// InjectContext makes data available to the child process and returns
+	// the context params that point at it. cleanup releases what was
+	// created once the child process has exited.
+	InjectContext(data *types.PipesContextData) (params map[string]any, cleanup func() error, err error)
+}
+
+// TempFileContextInjector writes the context data to a temporary file and
+// passes its path in the "path" param.
+type TempFileContextInjector struct {
+	// Dir is the directory of the temporary file. An empty Dir means the
+	// default directory for temporary files.
+	Dir string
+}
+
+func NewTempFileContextInjector() *TempFileContextInjector {
+	return &TempFileContextInjector{}
+}
+
+func (injector *TempFileContextInjector) InjectContext(data *types.PipesContextData) (map[string]any, func() error, error) {
+	f, err := os.CreateTemp(injector.Dir, "dagster-pipes-context-*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() error {
+		if err := os.Remove(f.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	err = json.NewEncoder(f).Encode(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("cannot write context: %w", err), cleanup())
+	}
+	return map[string]any{"path": f.Name()}, cleanup, nil
+}
+
+// InlineContextInjector passes the context data itself in the "data" param.
+// It suits small contexts, as the params end up in the environment of the
+// child process.
+type InlineContextInjector struct{}
+
+func NewInlineContextInjector() *InlineContextInjector {
+	return &InlineContextInjector{}
+}
+
+func (injector *InlineContextInjector) InjectContext(data *types.PipesContextData) (map[string]any, func() error, error) {
+	return map[string]any{"data": data}, func() error { return nil }, nil
+}
+
+// ContextEnv returns the environment variables that pass contextParams and
+// messageParams to a child process, in the "KEY=value" form of
+// os/exec.Cmd.Env:
+//
+//	params, cleanup, err := injector.InjectContext(data)
+//	...
+//	defer cleanup()
+//	env, err := dagster_pipes.ContextEnv(params, map[string]any{"path": messagesPath})
+//	...
+//	cmd.Env = append(os.Environ(), env...)
+func ContextEnv(contextParams, messageParams any) ([]string, error) {
+	context, err := EncodeEnvVar(contextParams)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := EncodeEnvVar(messageParams)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		DAGSTER_PIPES_CONTEXT_ENV_VAR + "=" + context,
+		DAGSTER_PIPES_MESSAGES_ENV_VAR + "=" + messages,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestEncodeEnvVar(t *testing.T) {
+	t.Parallel()
+	encoded, err := EncodeEnvVar(map[string]any{"path": "/tmp/context"})
+	require.NoError(t, err)
+
+	decoded, err := DecodeEnvVar(encoded)
+	require.NoError(t, err)
+	require.Equal(t, map[string]json.RawMessage{"path": json.RawMessage(`"/tmp/context"`)}, decoded)
+}
+
+func TestContextInjector(t *testing.T) {
+	t.Parallel()
+	data := &types.PipesContextData{
+		AssetKeys: []string{"my_asset"},
+		Extras:    map[string]any{"key": "value"},
+		RunID:     "012345",
+	}
+
+	tests := []struct {
+		name     string
+		injector ContextInjector
+	}{
+		{"temp file", &TempFileContextInjector{Dir: t.TempDir()}},
+		{"inline", NewInlineContextInjector()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			params, cleanup, err := tt.injector.InjectContext(data)
+			require.NoError(t, err)
+
+			env, err := ContextEnv(params, map[string]any{"stdio": "stdout"})
+			require.NoError(t, err)
+			require.Len(t, env, 2)
+
+			name, value, _ := strings.Cut(env[0], "=")
+			require.Equal(t, DAGSTER_PIPES_CONTEXT_ENV_VAR, name)
+			contextParams, err := DecodeEnvVar(value)
+			require.NoError(t, err)
+
+			contextData, err := NewDefaultContextLoader().LoadContext(contextParams)
+			require.NoError(t, err)
+			require.Equal(t, data, contextData)
+
+			name, value, _ = strings.Cut(env[1], "=")
+			require.Equal(t, DAGSTER_PIPES_MESSAGES_ENV_VAR, name)
+			messageParams, err := DecodeEnvVar(value)
+			require.NoError(t, err)
+			require.Equal(t, map[string]json.RawMessage{"stdio": json.RawMessage(`"stdout"`)}, messageParams)
+
+			require.NoError(t, cleanup())
+			if path, ok := params["path"].(string); ok {
+				require.NoFileExists(t, path)
+			}
+		})
+	}
+}
+
+func TestTempFileContextInjector_Cleanup(t *testing.T) {
+	t.Parallel()
+	params, cleanup, err := (&TempFileContextInjector{Dir: t.TempDir()}).InjectContext(&types.PipesContextData{})
+	require.NoError(t, err)
+
+	path := params["path"].(string)
+	require.FileExists(t, path)
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, cleanup())
+}
@@ -1,7 +1,11 @@
 package dagster_pipes
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/wingyplus/dagster-pipes-go/types"
@@ -11,6 +15,13 @@ type LoadContext interface {
 	LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error)
 }
 
+// LoadContextContext is implemented by loaders that fetch the context over
+// the network and can give up when ctx is done, such as the S3, GCS and Azure
+// loaders.
+type LoadContextContext interface {
+	LoadContextContext(ctx context.Context, params map[string]json.RawMessage) (*types.PipesContextData, error)
+}
+
 type PayloadErrorKind string
 
 func (e PayloadErrorKind) Error() string {
@@ -18,9 +29,37 @@ func (e PayloadErrorKind) Error() string {
 }
 
 var (
-	PayloadErrorKind_Missing PayloadErrorKind = "no payload found in params"
+	PayloadErrorKind_Missing    PayloadErrorKind = "no payload found in params"
+	PayloadErrorKind_Unreadable PayloadErrorKind = "cannot read payload"
+	PayloadErrorKind_Malformed  PayloadErrorKind = "malformed payload"
 )
 
+// PayloadError is returned when the context payload cannot be loaded, or
+// when the message params do not describe where to write messages. It
+// matches its Kind with errors.Is and wraps the underlying cause:
+//
+//	if errors.Is(err, dagster_pipes.PayloadErrorKind_Unreadable) {
+//	    // the payload file or object could not be read
+//	}
+type PayloadError struct {
+	Kind PayloadErrorKind
+	Err  error
+}
+
+func (e *PayloadError) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+func (e *PayloadError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
 type DefaultContextLoader struct{}
 
 func NewDefaultContextLoader() *DefaultContextLoader {
@@ -28,34 +67,59 @@ func NewDefaultContextLoader() *DefaultContextLoader {
 }
 
 func (loader *DefaultContextLoader) LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error) {
-	if pathJsonString, ok := params["path"]; ok {
-		var path string
-		if err := json.Unmarshal(pathJsonString, &path); err != nil {
-			// TODO: convert to PayloadErrorKind
+	if _, ok := params["path"]; ok {
+		path, err := payloadParam(params, "path")
+		if err != nil {
 			return nil, err
 		}
 
 		f, err := os.Open(path)
 		if err != nil {
-			// TODO: convert to PayloadErrorKind
-			return nil, err
+			return nil, &PayloadError{Kind: PayloadErrorKind_Unreadable, Err: err}
 		}
 		defer f.Close()
 
-		var contextData types.PipesContextData
-		if err := json.NewDecoder(f).Decode(&contextData); err != nil {
-			// TODO: convert to PayloadErrorKind
-			return nil, err
-		}
-		return &contextData, nil
+		return decodeContextData(f)
 	}
 	if data, ok := params["data"]; ok {
 		var contextData types.PipesContextData
 		if err := json.Unmarshal(data, &contextData); err != nil {
-			// TODO: convert to PayloadErrorKind
-			return nil, err
+			return nil, &PayloadError{Kind: PayloadErrorKind_Malformed, Err: err}
 		}
 		return &contextData, nil
 	}
 	return nil, PayloadErrorKind_Missing
 }
+
+// payloadParam reads the string value of key from the context or message
+// params. A missing or mistyped value is a malformed payload.
+func payloadParam(params map[string]json.RawMessage, key string) (string, error) {
+	value, err := stringParam(params, key)
+	if err != nil {
+		return "", &PayloadError{Kind: PayloadErrorKind_Malformed, Err: err}
+	}
+	return value, nil
+}
+
+// decodeContextData decodes the context data JSON read from r. Failing to
+// read r is reported as an unreadable payload, and invalid JSON as a
+// malformed one.
+func decodeContextData(r io.Reader) (*types.PipesContextData, error) {
+	var contextData types.PipesContextData
+	if err := json.NewDecoder(r).Decode(&contextData); err != nil {
+		return nil, &PayloadError{Kind: payloadDecodeErrorKind(err), Err: err}
+	}
+	return &contextData, nil
+}
+
+func payloadDecodeErrorKind(err error) PayloadErrorKind {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return PayloadErrorKind_Malformed
+	default:
+		return PayloadErrorKind_Unreadable
+	}
+}
@@ -2,7 +2,9 @@ package dagster_pipes
 
 import (
 	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -100,3 +102,40 @@ func TestDefaultLoader_LoadContext(t *testing.T) {
 		require.Equal(t, expectedFromPath, contextData)
 	})
 }
+
+func TestDefaultLoader_LoadContextErrors(t *testing.T) {
+	t.Parallel()
+	loader := NewDefaultContextLoader()
+
+	tests := []struct {
+		name   string
+		params map[string]json.RawMessage
+		kind   PayloadErrorKind
+	}{
+		{"missing payload", map[string]json.RawMessage{}, PayloadErrorKind_Missing},
+		{"path is not a string", map[string]json.RawMessage{"path": json.RawMessage(`1`)}, PayloadErrorKind_Malformed},
+		{"missing file", map[string]json.RawMessage{"path": json.RawMessage(`"/nonexistent/context.json"`)}, PayloadErrorKind_Unreadable},
+		{"malformed data", map[string]json.RawMessage{"data": json.RawMessage(`{"run_id": 1}`)}, PayloadErrorKind_Malformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := loader.LoadContext(tt.params)
+			require.ErrorIs(t, err, tt.kind)
+		})
+	}
+
+	t.Run("malformed file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "context.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"run_id":`), 0o644))
+
+		_, err := loader.LoadContext(map[string]json.RawMessage{
+			"path": json.RawMessage(`"` + path + `"`),
+		})
+		var payloadErr *PayloadError
+		require.ErrorAs(t, err, &payloadErr)
+		require.Equal(t, PayloadErrorKind_Malformed, payloadErr.Kind)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
@@ -0,0 +1,157 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestPipesContext_Context(t *testing.T) {
+	t.Parallel()
+	_, pipesContext := singleAssetFileAndContext(t)
+
+	ctx := pipesContext.Context()
+	require.NoError(t, ctx.Err())
+
+	fromContext, ok := FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, pipesContext, fromContext)
+
+	err := pipesContext.Close(nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, context.Cause(ctx), ErrPipesContextClosed)
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+	_, pipesContext := singleAssetFileAndContext(t)
+
+	_, ok := FromContext(t.Context())
+	require.False(t, ok)
+
+	ctx, cancel := context.WithCancel(NewContext(t.Context(), pipesContext))
+	defer cancel()
+	fromContext, ok := FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, pipesContext, fromContext)
+}
+
+func TestPipesContext_WithExtrasTimeout(t *testing.T) {
+	t.Parallel()
+	pipesContext := &PipesContext{
+		Data: &types.PipesContextData{
+			Extras: map[string]any{
+				"timeout_seconds":  float64(60),
+				"timeout_duration": "1h",
+				"timeout_number":   json.Number("60"),
+				"timeout_invalid":  true,
+			},
+		},
+	}
+
+	t.Run("seconds", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel, err := pipesContext.WithExtrasTimeout(t.Context(), "timeout_seconds")
+		require.NoError(t, err)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("json number", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel, err := pipesContext.WithExtrasTimeout(t.Context(), "timeout_number")
+		require.NoError(t, err)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("duration string", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel, err := pipesContext.WithExtrasTimeout(t.Context(), "timeout_duration")
+		require.NoError(t, err)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Hour), deadline, 5*time.Second)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel, err := pipesContext.WithExtrasTimeout(t.Context(), "missing")
+		require.NoError(t, err)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		require.False(t, ok)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := pipesContext.WithExtrasTimeout(t.Context(), "timeout_invalid")
+		require.ErrorContains(t, err, "timeout_invalid")
+	})
+}
+
+func TestReportContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes when the context is live", func(t *testing.T) {
+		t.Parallel()
+		file, pipesContext := singleAssetFileAndContext(t)
+
+		err := pipesContext.ReportCustomMessageContext(t.Context(), "payload")
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(file.Path)
+		require.NoError(t, err)
+		require.NotEmpty(t, content)
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		t.Parallel()
+		file, pipesContext := singleAssetFileAndContext(t)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err := pipesContext.ReportAssetMaterializationContext(ctx, "asset1", nil, "v1")
+		require.ErrorIs(t, err, context.Canceled)
+		err = pipesContext.ReportAssetCheckContext(ctx, "check", true, "asset1", nil, nil)
+		require.ErrorIs(t, err, context.Canceled)
+
+		content, err := os.ReadFile(file.Path)
+		require.NoError(t, err)
+		require.Empty(t, content)
+	})
+
+	t.Run("stops waiting for a full queue", func(t *testing.T) {
+		t.Parallel()
+		inner := &recordingChannel{release: make(chan struct{})}
+		channel := NewAsyncChannel(inner, AsyncChannelOptions{QueueSize: 1, BatchSize: 1})
+		pipesContext := &PipesContext{Channel: channel, Data: &types.PipesContextData{}}
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		var err error
+		for range 3 {
+			err = pipesContext.ReportCustomMessageContext(ctx, "payload")
+		}
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(inner.release)
+		require.NoError(t, pipesContext.Close(nil))
+	})
+}
@@ -3,13 +3,38 @@ package dagster_pipes
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/wingyplus/dagster-pipes-go/types"
 )
 
 var ErrMissingAssetKey = errors.New("asset key is missing")
 
+// ErrPipesContextClosed matches the error returned when reporting through a
+// closed PipesContext:
+//
+//	if errors.Is(err, dagster_pipes.ErrPipesContextClosed) {
+//	    // the session is over
+//	}
+var ErrPipesContextClosed = errors.New("pipes context is closed")
+
+// ClosedError is returned when a message is reported after the PipesContext
+// has been closed.
+type ClosedError struct {
+	// Method is the method of the rejected message.
+	Method types.Method
+}
+
+func (e *ClosedError) Error() string {
+	return fmt.Sprintf("cannot send %s message: %s", e.Method, ErrPipesContextClosed)
+}
+
+func (e *ClosedError) Is(target error) bool {
+	return target == ErrPipesContextClosed
+}
+
 type Metadata map[string]*types.PipesMetadataValue
 
 // PipesContext represents the connection to Dagster and provides methods
@@ -23,9 +48,40 @@ type PipesContext struct {
 	Data *types.PipesContextData
 	// Channel is the communication channel for sending messages back to Dagster.
 	Channel MessageWriterChannel
+
+	// mu guards state, forwarders, logger and stopSignals. Reports hold it
+	// for reading while they write, so that Close waits for in-flight
+	// reports.
+	mu         sync.RWMutex
+	state      pipesContextState
+	forwarders []*ExternalStreamForwarder
+	// logger is created on first use by Log.
+	logger *PipesLogger
+	// stopSignals stops the handler installed by HandleSignals.
+	stopSignals func()
+
+	// session is created on first use, so that a PipesContext built as a
+	// struct literal works too.
+	sessionOnce sync.Once
+	session     *session
 }
 
+// pipesContextState is the lifecycle state of a PipesContext. A context
+// starts opened and moves to closed exactly once.
+type pipesContextState int
+
+const (
+	pipesContextOpened pipesContextState = iota
+	pipesContextClosed
+)
+
 // Close sends a close message to Dagster and terminates the pipes connection.
+// The channel is flushed and closed after the close message is written,
+// external streams forwarded with ForwardExternalStreams are restored, and
+// the context returned by Context is cancelled.
+//
+// Once closed, the context rejects further reports with a ClosedError, and
+// calling Close again does nothing.
 //
 // This should be called when your application is done communicating with Dagster.
 // It's recommended to use defer to ensure Close is called even if an error occurs:
@@ -41,14 +97,33 @@ type PipesContext struct {
 //
 //	defer func() {
 //	    if r := recover(); r != nil {
-//	        exc := &types.PipesException{
-//	            Message: helper.Ptr(fmt.Sprintf("%v", r)),
-//	            Name:    helper.Ptr("panic"),
-//	        }
-//	        context.Close(exc)
+//	        context.Close(dagster_pipes.PipesExceptionPanic(r))
 //	    }
 //	}()
+//
+// Run does all of the above for you.
 func (context *PipesContext) Close(exception *types.PipesException) error {
+	// Forwarders report through the context, so they are stopped before
+	// taking the lock.
+	context.mu.Lock()
+	forwarders := context.forwarders
+	context.forwarders = nil
+	context.mu.Unlock()
+
+	var errs []error
+	for _, forwarder := range forwarders {
+		errs = append(errs, forwarder.Stop())
+	}
+
+	context.mu.Lock()
+	defer context.mu.Unlock()
+
+	if context.state == pipesContextClosed {
+		return errors.Join(errs...)
+	}
+	context.state = pipesContextClosed
+	context.getSession().end(ErrPipesContextClosed)
+
 	var params map[string]any = nil
 	if exception != nil {
 		params = map[string]any{
@@ -60,7 +135,36 @@ func (context *PipesContext) Close(exception *types.PipesException) error {
 		}
 	}
 	closedMessage := types.NewMessage(types.Closed, params)
-	return context.Channel.Write(closedMessage)
+	errs = append(errs, context.Channel.Write(closedMessage), context.Channel.Close())
+	return errors.Join(errs...)
+}
+
+// Flush pushes out messages buffered by the channel. It does nothing once
+// the context is closed, as closing already flushed the channel.
+func (context *PipesContext) Flush() error {
+	context.mu.RLock()
+	defer context.mu.RUnlock()
+
+	if context.state == pipesContextClosed {
+		return nil
+	}
+	return context.Channel.Flush()
+}
+
+// write sends message through the channel unless the context is closed.
+func (context *PipesContext) write(message *types.PipesMessage) error {
+	return context.send(message, context.Channel.Write)
+}
+
+// send passes message to write unless the context is closed.
+func (context *PipesContext) send(message *types.PipesMessage, write func(*types.PipesMessage) error) error {
+	context.mu.RLock()
+	defer context.mu.RUnlock()
+
+	if context.state == pipesContextClosed {
+		return &ClosedError{Method: message.Method}
+	}
+	return write(message)
 }
 
 // ReportAssetMaterialization reports an asset materialization to Dagster.
@@ -90,20 +194,32 @@ func (context *PipesContext) ReportAssetMaterialization(
 	metadata Metadata,
 	dataVersion string,
 ) error {
-	ak, err := context.resolveOptionallyPassedAssetKey(assetKey)
+	message, err := context.assetMaterializationMessage(assetKey, metadata, dataVersion)
 	if err != nil {
 		return err
 	}
+	return context.write(message)
+}
+
+func (context *PipesContext) assetMaterializationMessage(
+	assetKey string,
+	metadata Metadata,
+	dataVersion string,
+) (*types.PipesMessage, error) {
+	ak, err := context.resolveOptionallyPassedAssetKey(assetKey)
+	if err != nil {
+		return nil, err
+	}
 
 	var params = map[string]any{
 		"asset_key":    ak,
 		"metadata":     metadata,
 		"data_version": stringOrNil(dataVersion),
 	}
-	return context.Channel.Write(&types.PipesMessage{
+	return &types.PipesMessage{
 		Method: types.ReportAssetMaterialization,
 		Params: params,
-	})
+	}, nil
 }
 
 func (context *PipesContext) resolveOptionallyPassedAssetKey(assetKey string) (string, error) {
@@ -148,10 +264,24 @@ func (context *PipesContext) ReportAssetCheck(
 	severity *types.AssetCheckSeverity,
 	metadata Metadata,
 ) error {
-	ak, err := context.resolveOptionallyPassedAssetKey(assetKey)
+	message, err := context.assetCheckMessage(checkName, passed, assetKey, severity, metadata)
 	if err != nil {
 		return err
 	}
+	return context.write(message)
+}
+
+func (context *PipesContext) assetCheckMessage(
+	checkName string,
+	passed bool,
+	assetKey string,
+	severity *types.AssetCheckSeverity,
+	metadata Metadata,
+) (*types.PipesMessage, error) {
+	ak, err := context.resolveOptionallyPassedAssetKey(assetKey)
+	if err != nil {
+		return nil, err
+	}
 	var params = map[string]any{
 		"asset_key":  ak,
 		"check_name": checkName,
@@ -159,7 +289,7 @@ func (context *PipesContext) ReportAssetCheck(
 		"severity":   severity,
 		"metadata":   metadata,
 	}
-	return context.Channel.Write(types.NewMessage(types.ReportAssetCheck, params))
+	return types.NewMessage(types.ReportAssetCheck, params), nil
 }
 
 // ReportCustomMessage sends a custom message payload to Dagster.
@@ -176,10 +306,14 @@ func (context *PipesContext) ReportAssetCheck(
 //	    "items_processed": 5000,
 //	})
 func (context *PipesContext) ReportCustomMessage(payload any) error {
+	return context.write(customMessage(payload))
+}
+
+func customMessage(payload any) *types.PipesMessage {
 	var params = map[string]any{
 		"payload": payload,
 	}
-	return context.Channel.Write(types.NewMessage(types.ReportCustomMessage, params))
+	return types.NewMessage(types.ReportCustomMessage, params)
 }
 
 // NewPipesContext creates a new PipesContext with the given parameters.
@@ -196,15 +330,16 @@ func (context *PipesContext) ReportCustomMessage(payload any) error {
 // The function opens a message channel, sends an "opened" message to Dagster
 // to signal that the pipes connection is established, and returns the context.
 func NewPipesContext(contextData *types.PipesContextData, messageParams map[string]json.RawMessage, messageWriter MessageWriter) (*PipesContext, error) {
-	channel := messageWriter.Open(messageParams)
-	// TODO: initialize PipesLogger
+	channel, err := messageWriter.Open(messageParams)
+	if err != nil {
+		return nil, err
+	}
 
 	openedPayload := messageWriter.GetOpenedPayload()
 	openedMessage := &types.PipesMessage{Method: types.Opened, Params: openedPayload}
 
 	if err := channel.Write(openedMessage); err != nil {
-		// TODO: wrap error
-		return nil, err
+		return nil, errors.Join(fmt.Errorf("cannot write opened message: %w", err), channel.Close())
 	}
 
 	return &PipesContext{
@@ -213,6 +348,25 @@ func NewPipesContext(contextData *types.PipesContextData, messageParams map[stri
 	}, nil
 }
 
+// Log returns the logger that sends log messages to the Dagster event log.
+// It is created on first use, so that a PipesContext built as a struct
+// literal has one too.
+func (context *PipesContext) Log() *PipesLogger {
+	context.mu.RLock()
+	logger := context.logger
+	context.mu.RUnlock()
+	if logger != nil {
+		return logger
+	}
+
+	context.mu.Lock()
+	defer context.mu.Unlock()
+	if context.logger == nil {
+		context.logger = NewPipesLogger(context)
+	}
+	return context.logger
+}
+
 // OpenDasterPipes opens a connection to Dagster and returns a PipesContext.
 //
 // This is the main entry point for using Dagster Pipes in your Go application.
@@ -221,7 +375,8 @@ func NewPipesContext(contextData *types.PipesContextData, messageParams map[stri
 // to report materializations, checks, and custom messages.
 //
 // The function uses default implementations for:
-//   - Parameter loading (from environment variables)
+//   - Parameter loading (from environment variables, or from command-line
+//     arguments when the environment variables are not set)
 //   - Context loading (deserializing Dagster context data)
 //   - Message writing (file-based or stdout-based communication)
 //
@@ -239,7 +394,7 @@ func NewPipesContext(contextData *types.PipesContextData, messageParams map[stri
 // Returns an error if the environment is not properly configured for Dagster Pipes
 // or if communication with Dagster cannot be established.
 func OpenDasterPipes() (*PipesContext, error) {
-	paramsLoader := NewEnvVarLoader()
+	paramsLoader := NewCompositeLoader(NewEnvVarLoader(), NewCLIArgLoader())
 	contextLoader := NewDefaultContextLoader()
 	messageWriter := NewDefaultMessageWriter()
 
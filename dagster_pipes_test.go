@@ -1,6 +1,7 @@
 package dagster_pipes
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"os"
@@ -87,17 +88,42 @@ func TestClosePipesContext(t *testing.T) {
 	err = json.Unmarshal(content, &message)
 	require.NoError(t, err)
 
-	require.Equal(t, &types.PipesMessage{
-		DagsterPipesVersion: "0.1",
-		Method:              types.Closed,
-		Params: map[string]any{
-			"cause":   nil,
-			"context": nil,
-			"message": "some error",
-			"name":    "some error",
-			"stack":   nil,
-		},
-	}, &message)
+	require.Equal(t, "0.1", message.DagsterPipesVersion)
+	require.Equal(t, types.Closed, message.Method)
+	require.Nil(t, message.Params["cause"])
+	require.Nil(t, message.Params["context"])
+	require.Equal(t, "some error", message.Params["message"])
+	require.Equal(t, "*errors.errorString", message.Params["name"])
+	require.NotEmpty(t, message.Params["stack"])
+}
+
+func TestClosePipesContextTwice(t *testing.T) {
+	t.Parallel()
+	file, context := singleAssetFileAndContext(t)
+
+	err := context.Close(nil)
+	require.NoError(t, err)
+	err = context.Close(nil)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+	require.Equal(t, 1, bytes.Count(content, []byte("\n")))
+}
+
+func TestReportAfterClose(t *testing.T) {
+	t.Parallel()
+	_, context := singleAssetFileAndContext(t)
+
+	err := context.Close(nil)
+	require.NoError(t, err)
+
+	err = context.ReportCustomMessage("payload")
+	require.ErrorIs(t, err, ErrPipesContextClosed)
+
+	var closedErr *ClosedError
+	require.ErrorAs(t, err, &closedErr)
+	require.Equal(t, types.ReportCustomMessage, closedErr.Method)
 }
 
 func singleAssetFileAndContext(t *testing.T) (*FileChannel, *PipesContext) {
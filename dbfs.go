@@ -0,0 +1,91 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// DefaultDBFSRoot is where DBFS is mounted on Databricks cluster nodes.
+const DefaultDBFSRoot = "/dbfs"
+
+// NewDBFSMessageWriter creates a MessageWriter that writes message chunks as
+// numbered files into the DBFS directory named by the "path" message param,
+// the layout PipesDbfsMessageReader expects.
+//
+// root is the local mount point of DBFS. An empty root means DefaultDBFSRoot.
+func NewDBFSMessageWriter(root string) *BlobStoreMessageWriter {
+	return NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		path, err := payloadParam(params, "path")
+		if err != nil {
+			return nil, err
+		}
+		dir := dbfsLocalPath(root, path)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		return &dbfsChunkUploader{dir: dir}, nil
+	})
+}
+
+type dbfsChunkUploader struct {
+	dir string
+}
+
+// UploadChunk writes the chunk to a temporary file first and renames it into
+// place, so the reader never sees a partially written chunk. Local file
+// writes cannot be interrupted, so ctx is only checked before writing.
+func (uploader *dbfsChunkUploader) UploadChunk(ctx context.Context, index int, chunk []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name := filepath.Join(uploader.dir, fmt.Sprintf("%d.json", index))
+	tmp := filepath.Join(uploader.dir, fmt.Sprintf(".%d.json.tmp", index))
+	if err := os.WriteFile(tmp, chunk, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// DBFSContextLoader loads the context data from the DBFS file named by the
+// "path" context param, as written by PipesDbfsContextInjector.
+type DBFSContextLoader struct {
+	// Root is the local mount point of DBFS.
+	Root string
+}
+
+// NewDBFSContextLoader creates a DBFSContextLoader that resolves paths under
+// root. An empty root means DefaultDBFSRoot.
+func NewDBFSContextLoader(root string) *DBFSContextLoader {
+	return &DBFSContextLoader{Root: root}
+}
+
+func (loader *DBFSContextLoader) LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	path, err := payloadParam(params, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(dbfsLocalPath(loader.Root, path))
+	if err != nil {
+		return nil, &PayloadError{Kind: PayloadErrorKind_Unreadable, Err: err}
+	}
+	defer f.Close()
+
+	return decodeContextData(f)
+}
+
+// dbfsLocalPath maps a DBFS path, with or without the "dbfs:" scheme, to its
+// location under the local mount point root.
+func dbfsLocalPath(root, path string) string {
+	if root == "" {
+		root = DefaultDBFSRoot
+	}
+	path = strings.TrimPrefix(path, "dbfs:")
+	return filepath.Join(root, filepath.FromSlash(strings.TrimLeft(path, "/")))
+}
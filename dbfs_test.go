@@ -0,0 +1,65 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestDBFSMessageWriter(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	writer := NewDBFSMessageWriter(root)
+	channel, err := writer.Open(map[string]json.RawMessage{
+		"path": json.RawMessage(`"/tmp/messages"`),
+	})
+	require.NoError(t, err)
+
+	err = channel.Write(types.NewMessage(types.Opened, nil))
+	require.NoError(t, err)
+	err = channel.(*BlobStoreChannel).Close()
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(root, "tmp", "messages", "1.json"))
+	require.NoError(t, err)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(content, &message)
+	require.NoError(t, err)
+	require.Equal(t, types.Opened, message.Method)
+}
+
+func TestDBFSContextLoader(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "tmp"), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(root, "tmp", "context.json"), []byte(`{"run_id": "012345", "extras": {}}`), 0o644)
+	require.NoError(t, err)
+
+	loader := NewDBFSContextLoader(root)
+	expected := &types.PipesContextData{RunID: "012345", Extras: map[string]any{}}
+
+	t.Run("path without scheme", func(t *testing.T) {
+		t.Parallel()
+		contextData, err := loader.LoadContext(map[string]json.RawMessage{
+			"path": json.RawMessage(`"/tmp/context.json"`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, contextData)
+	})
+
+	t.Run("path with scheme", func(t *testing.T) {
+		t.Parallel()
+		contextData, err := loader.LoadContext(map[string]json.RawMessage{
+			"path": json.RawMessage(`"dbfs:/tmp/context.json"`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, contextData)
+	})
+}
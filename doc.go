@@ -11,7 +11,7 @@ through Dagster. With this lightweight interface, you can:
   - Report asset materializations with rich metadata
   - Report asset checks for data quality validation
   - Send custom structured messages to Dagster
-  - Provide structured logging (planned feature)
+  - Send log messages to the Dagster event log
 
 # Installation
 
@@ -85,6 +85,22 @@ To run your Go application from Dagster, use PipesSubprocessClient:
 	    },
 	)
 
+Launchers that cannot set environment variables can pass the same values as
+the --dagster-pipes-context and --dagster-pipes-messages arguments instead.
+Strip them before parsing the application's own flags:
+
+	flag.CommandLine.Parse(dagster_pipes.StripCLIArgs(os.Args[1:]))
+
+To combine other sources, such as a dotenv file for local development,
+chain loaders with a CompositeLoader. The first loader that has a param
+wins, and the error lists every source that was tried:
+
+	loader := dagster_pipes.NewCompositeLoader(
+	    dagster_pipes.NewDotEnvLoader(".env.pipes"),
+	    dagster_pipes.NewEnvVarLoader(),
+	    dagster_pipes.NewCLIArgLoader(),
+	)
+
 # Metadata Types
 
 The metadata package provides helpers for all supported Dagster metadata types:
@@ -131,21 +147,112 @@ Send arbitrary structured data to Dagster:
 	    "items_processed": 7500,
 	})
 
+# Logging
+
+Send log messages to the event log of the Dagster run with the leveled
+helpers of the logger returned by PipesContext.Log:
+
+	context.Log().Info("processing started")
+	context.Log().Warning("input is empty, skipping")
+	context.Log().Error("cannot reach the warehouse")
+
+Programs that already log with log/slog can route their logs to Dagster
+with a SlogHandler:
+
+	slog.SetDefault(slog.New(dagster_pipes.NewSlogHandler(context, nil)))
+
+# External Streams
+
+Forward what the process writes to stdout and stderr, including output of C
+libraries to stdout on Unix, to Dagster while still printing it locally:
+
+	if _, err := context.ForwardExternalStreams(nil); err != nil {
+	    log.Fatal(err)
+	}
+
+The streams are restored when the context is closed. Panics and fatal errors
+of the Go runtime are written straight to the original stderr, unless
+ExternalStreamOptions.CaptureStderrDescriptor is set. See
+ExternalStreamForwarder for what is captured on each platform.
+
 # Error Handling
 
 To report exceptions to Dagster, pass a PipesException to Close:
 
 	defer func() {
 	    if r := recover(); r != nil {
-	        exc := &types.PipesException{
-	            Message: helper.Ptr(fmt.Sprintf("panic: %v", r)),
-	            Name:    helper.Ptr("panic"),
-	            Stack:   debug.Stack(), // if you want stack trace
-	        }
-	        context.Close(exc)
+	        context.Close(dagster_pipes.PipesExceptionPanic(r))
 	    }
 	}()
 
+PipesExceptionError converts a Go error into a PipesException. Wrapped and
+joined errors are reported as the cause and context of the exception, and
+the stack trace is captured where the conversion happens:
+
+	if err := process(); err != nil {
+	    context.Close(dagster_pipes.PipesExceptionError(err))
+	}
+
+Failures of OpenDasterPipes are typed, so a wrapper can tell a process that
+was not launched by Dagster from one that received a broken payload:
+
+	context, err := dagster_pipes.OpenDasterPipes()
+	switch {
+	case errors.Is(err, dagster_pipes.ParamsErrorKind_NotPresent):
+	    // not launched by Dagster
+	case errors.Is(err, dagster_pipes.ParamsErrorKind_Invalid),
+	    errors.Is(err, dagster_pipes.PayloadErrorKind_Missing),
+	    errors.Is(err, dagster_pipes.PayloadErrorKind_Malformed):
+	    // Dagster sent something that cannot be decoded or used
+	case errors.Is(err, dagster_pipes.PayloadErrorKind_Unreadable):
+	    // the context payload could not be read
+	}
+
+Run wraps a whole program in a session. It reports a returned error or a
+panic to Dagster, always writes the closed message and exits with a non-zero
+status on failure:
+
+	func main() {
+	    dagster_pipes.Run(func(context *dagster_pipes.PipesContext) error {
+	        return process(context)
+	    })
+	}
+
+# Graceful Shutdown
+
+When a run is cancelled, the process receives SIGTERM. HandleSignals cancels
+the session context, gives the program a grace period to wind down, and then
+closes the session with an exception describing the interruption:
+
+	stop := context.HandleSignals(&dagster_pipes.SignalOptions{
+	    GracePeriod: 30 * time.Second,
+	})
+	defer stop()
+
+	err := work(context.Context())
+
+Run installs HandleSignals with the default options. Programs that open the
+session themselves must call it, or buffered messages are lost when the
+process is killed.
+
+# Cancellation and Deadlines
+
+Every report method has a variant that takes a context.Context, such as
+ReportAssetMaterializationContext. The session context returned by
+PipesContext.Context carries the PipesContext, so nested library code can
+report without it being passed down:
+
+	func process(ctx context.Context) error {
+	    if pipesContext, ok := dagster_pipes.FromContext(ctx); ok {
+	        pipesContext.Log().Info("processing")
+	    }
+	    return nil
+	}
+
+WithExtrasTimeout derives a deadline from a value in the extras:
+
+	ctx, cancel, err := context.WithExtrasTimeout(context.Context(), "timeout")
+
 # Context Data
 
 The PipesContext.Data field contains information passed from Dagster:
@@ -165,6 +272,56 @@ Access this data to make your application context-aware:
 	    // Process specific partition
 	}
 
+Partitioned runs can parse the partition time window, split a backfill range
+into daily, hourly or monthly windows, and name the dimensions of a
+multi-partition key:
+
+	window, ok, err := context.PartitionTimeWindow(time.UTC)
+	if ok && context.IsPartitionRange() {
+	    for day := range window.Split(dagster_pipes.Daily) {
+	        processDay(day.Start, day.End)
+	    }
+	}
+
+	keys, err := context.MultiPartitionKey("date", "region")
+
+IsStale compares the provenance of the last materialization with the current
+upstream data versions and code version, so that expensive work can be
+skipped when nothing changed:
+
+	stale, err := context.IsStale("my_dataset", upstreamVersions)
+	if err == nil && !stale {
+	    return context.ReportAssetMaterialization("my_dataset", nil, lastVersion)
+	}
+
+Read extras with GetExtra, or decode them all into a struct with
+DecodeExtras:
+
+	batchSize, err := dagster_pipes.GetExtra[int](context, "batch_size")
+
+	var config struct {
+	    Table string `extras:"table,required"`
+	    Limit int    `extras:"limit,default=1000"`
+	}
+	err = context.DecodeExtras(&config)
+
+# Launching Pipes Processes
+
+A ContextInjector and ContextEnv produce the environment of a child process
+that speaks Dagster Pipes, which is also handy to build params in tests:
+
+	params, cleanup, err := dagster_pipes.NewTempFileContextInjector().InjectContext(data)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	defer cleanup()
+
+	env, err := dagster_pipes.ContextEnv(params, map[string]any{"path": messagesPath})
+	cmd.Env = append(os.Environ(), env...)
+
+The client package does all of this: it launches the process, reads its
+messages as they arrive and returns the events it reported.
+
 # More Information
 
 For more information about Dagster Pipes:
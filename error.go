@@ -1,13 +1,159 @@
 package dagster_pipes
 
 import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+
 	"github.com/wingyplus/dagster-pipes-go/internal/helper"
 	"github.com/wingyplus/dagster-pipes-go/types"
 )
 
+// maxStackDepth is the maximum number of frames captured for a stack trace.
+const maxStackDepth = 64
+
+// PipesExceptionError converts err into a PipesException to report to
+// Dagster through PipesContext.Close.
+//
+// The exception is named after the concrete type of err. Errors wrapped with
+// fmt.Errorf("...: %w", err) or a custom Unwrap method become the Cause of
+// the exception. For errors joined with errors.Join, the first error becomes
+// the Cause and each following error is chained through Context.
+//
+// The stack trace is taken from err when it carries one, as errors from
+// github.com/pkg/errors do, and is captured at the call site otherwise.
 func PipesExceptionError(err error) *types.PipesException {
-	return &types.PipesException{
+	if err == nil {
+		return nil
+	}
+	exception := (*types.PipesException)(exceptionClass(err))
+	if exception.Stack == nil {
+		exception.Stack = callerStack(1)
+	}
+	return exception
+}
+
+// PipesExceptionPanic converts a value recovered from a panic into a
+// PipesException. It must be called from the deferred function that
+// recovered, so that the stack trace includes the panicking frames:
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        context.Close(dagster_pipes.PipesExceptionPanic(r))
+//	    }
+//	}()
+//
+// Panics with an error value are converted like PipesExceptionError. Other
+// values are reported as an exception named "panic".
+func PipesExceptionPanic(value any) *types.PipesException {
+	var exception *types.PipesException
+	if err, ok := value.(error); ok {
+		exception = (*types.PipesException)(exceptionClass(err))
+	} else {
+		exception = &types.PipesException{
+			Message: helper.Ptr(fmt.Sprint(value)),
+			Name:    helper.Ptr("panic"),
+		}
+	}
+	if exception.Stack == nil {
+		exception.Stack = callerStack(1)
+	}
+	return exception
+}
+
+func exceptionClass(err error) *types.PipesExceptionClass {
+	exception := &types.PipesExceptionClass{
 		Message: helper.Ptr(err.Error()),
-		Name:    helper.Ptr(err.Error()),
+		Name:    helper.Ptr(reflect.TypeOf(err).String()),
+		Stack:   errorStack(err),
+	}
+
+	switch err := err.(type) {
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, e := range err.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 0 {
+			break
+		}
+		exception.Cause = exceptionClass(errs[0])
+		for i := len(errs) - 1; i > 0; i-- {
+			context := (*types.ContextClass)(exceptionClass(errs[i]))
+			appendContext(context, exception.Context)
+			exception.Context = context
+		}
+	case interface{ Unwrap() error }:
+		if cause := err.Unwrap(); cause != nil {
+			exception.Cause = exceptionClass(cause)
+		}
+	}
+	return exception
+}
+
+// appendContext attaches next at the end of the context chain of context.
+func appendContext(context *types.ContextClass, next *types.ContextClass) {
+	for context.Context != nil {
+		context = context.Context
+	}
+	context.Context = next
+}
+
+// errorStack returns the stack trace carried by err, or nil if it has none.
+//
+// It understands errors with a Callers() []uintptr method, and errors with a
+// StackTrace method returning a slice of program counters, such as the
+// errors.StackTrace of github.com/pkg/errors.
+func errorStack(err error) []string {
+	if err, ok := err.(interface{ Callers() []uintptr }); ok {
+		return formatStack(err.Callers())
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	out := method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	frames := method.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return formatStack(pcs)
+}
+
+// callerStack captures the stack of the caller, skipping skip extra frames.
+func callerStack(skip int) []string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return formatStack(pcs[:n])
+}
+
+// formatStack formats program counters the way Python formats traceback
+// entries, which is how Dagster renders stack traces. Like a Python
+// traceback, the most recent call comes last.
+func formatStack(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var stack []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			stack = append(stack, fmt.Sprintf("  File \"%s\", line %d, in %s\n", frame.File, frame.Line, frame.Function))
+		}
+		if !more {
+			break
+		}
 	}
+	slices.Reverse(stack)
+	return stack
 }
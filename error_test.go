@@ -0,0 +1,77 @@
+package dagster_pipes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stackError struct {
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string {
+	return "stack error"
+}
+
+func (e *stackError) Callers() []uintptr {
+	return e.pcs
+}
+
+func TestPipesExceptionError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil error", func(t *testing.T) {
+		t.Parallel()
+		require.Nil(t, PipesExceptionError(nil))
+	})
+
+	t.Run("captures the caller stack", func(t *testing.T) {
+		t.Parallel()
+		exception := PipesExceptionError(errors.New("some error"))
+		require.Equal(t, "*errors.errorString", *exception.Name)
+		require.Equal(t, "some error", *exception.Message)
+		require.NotEmpty(t, exception.Stack)
+		require.Contains(t, exception.Stack[len(exception.Stack)-1], "TestPipesExceptionError")
+	})
+
+	t.Run("wrapped errors become the cause", func(t *testing.T) {
+		t.Parallel()
+		pathErr := &fs.PathError{Op: "open", Path: "context.json", Err: fs.ErrNotExist}
+		err := fmt.Errorf("load context: %w", pathErr)
+
+		exception := PipesExceptionError(err)
+		require.Equal(t, "*fmt.wrapError", *exception.Name)
+		require.Equal(t, "*fs.PathError", *exception.Cause.Name)
+		require.Equal(t, "open context.json: file does not exist", *exception.Cause.Message)
+		require.Equal(t, "*errors.errorString", *exception.Cause.Cause.Name)
+		require.Nil(t, exception.Cause.Cause.Cause)
+		require.Nil(t, exception.Cause.Stack)
+	})
+
+	t.Run("joined errors become the cause and context", func(t *testing.T) {
+		t.Parallel()
+		err := errors.Join(errors.New("first"), errors.New("second"), errors.New("third"))
+
+		exception := PipesExceptionError(err)
+		require.Equal(t, "*errors.joinError", *exception.Name)
+		require.Equal(t, "first", *exception.Cause.Message)
+		require.Equal(t, "second", *exception.Context.Message)
+		require.Equal(t, "third", *exception.Context.Context.Message)
+		require.Nil(t, exception.Context.Context.Context)
+	})
+
+	t.Run("uses the stack carried by the error", func(t *testing.T) {
+		t.Parallel()
+		pcs := make([]uintptr, 1)
+		runtime.Callers(1, pcs)
+
+		exception := PipesExceptionError(&stackError{pcs: pcs})
+		require.Len(t, exception.Stack, 1)
+		require.Contains(t, exception.Stack[0], "TestPipesExceptionError")
+	})
+}
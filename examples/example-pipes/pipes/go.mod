@@ -5,3 +5,5 @@ go 1.25.3
 replace github.com/wingyplus/dagster-pipes-go => ../../..
 
 require github.com/wingyplus/dagster-pipes-go v0.0.0-00010101000000-000000000000
+
+require golang.org/x/sys v0.30.0 // indirect
@@ -0,0 +1,237 @@
+package dagster_pipes
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// externalStreamChunkSize is the largest chunk of output sent in a single
+// log_external_stream message.
+const externalStreamChunkSize = 32 * 1024
+
+// ErrExternalStreamsForwarded is returned by
+// PipesContext.ForwardExternalStreams when another forwarder is still active.
+var ErrExternalStreamsForwarded = errors.New("external streams are already forwarded")
+
+// activeForwarder is the forwarder that currently captures the process
+// streams. The streams belong to the process, so only one forwarder at a
+// time can capture them.
+var (
+	activeForwarderMu sync.Mutex
+	activeForwarder   *ExternalStreamForwarder
+)
+
+// ExternalStreamOptions configures PipesContext.ForwardExternalStreams.
+type ExternalStreamOptions struct {
+	// CaptureStderrDescriptor makes the forwarder redirect the stderr file
+	// descriptor on Unix, as it does for stdout, instead of replacing
+	// os.Stderr. Output of C libraries and of the default logger of the log
+	// package is then captured too, but panics and fatal errors of the Go
+	// runtime go through the pipe and are lost when the process dies before
+	// they are forwarded. It has no effect on other platforms.
+	CaptureStderrDescriptor bool
+}
+
+// ExternalStreamForwarder captures what the process writes to its stdout and
+// stderr and forwards it to Dagster as log_external_stream messages. The
+// output is still passed through to the original streams.
+//
+// On Unix the stdout file descriptor itself is redirected, so output written
+// to it by C libraries is captured as well. The stderr file descriptor is
+// only redirected with ExternalStreamOptions.CaptureStderrDescriptor, so that
+// by default panics and fatal errors of the Go runtime, which are written
+// straight to it, still reach the original stream when the process dies
+// before the captured output is forwarded.
+//
+// A stream whose file descriptor is not redirected, stderr by default and
+// both streams on platforms other than Unix, is captured by replacing
+// os.Stderr or os.Stdout. This has two limits:
+//   - the variable is replaced and restored without synchronization, so no
+//     other goroutine may write to the stream while forwarding starts or
+//     stops;
+//   - only writes through the variable are captured. Output of C code and of
+//     writers that kept the original file, such as the default logger of the
+//     log package, goes to the original stream only.
+//
+// Only one forwarder can be active in a process at a time.
+type ExternalStreamForwarder struct {
+	streams  []*externalStream
+	stopOnce sync.Once
+	stopErr  error
+}
+
+type externalStream struct {
+	name        string
+	reader      *os.File
+	writer      *os.File
+	redirection *redirection
+	done        chan struct{}
+}
+
+// redirection is a stream redirected into a pipe.
+type redirection struct {
+	// original still writes to where the stream pointed before.
+	original *os.File
+	// restore points the stream back at its original destination.
+	restore func() error
+	// release frees original once nothing writes to it anymore.
+	release func() error
+}
+
+// ForwardExternalStreams starts capturing stdout and stderr. Capturing stops
+// when the returned forwarder is stopped or when the context is closed. It
+// returns ErrExternalStreamsForwarded while another forwarder is active.
+// Passing nil uses the default options.
+//
+// A stream that the message channel itself writes to, as with the stdio
+// channels, is left alone so that messages are not forwarded back to Dagster.
+func (context *PipesContext) ForwardExternalStreams(options *ExternalStreamOptions) (*ExternalStreamForwarder, error) {
+	if options == nil {
+		options = &ExternalStreamOptions{}
+	}
+	forwarder := &ExternalStreamForwarder{}
+
+	activeForwarderMu.Lock()
+	if activeForwarder != nil {
+		activeForwarderMu.Unlock()
+		return nil, ErrExternalStreamsForwarded
+	}
+	activeForwarder = forwarder
+	activeForwarderMu.Unlock()
+
+	redirectStderr := replaceStream
+	if options.CaptureStderrDescriptor {
+		redirectStderr = redirectStream
+	}
+
+	for _, stream := range []struct {
+		name     string
+		file     **os.File
+		redirect func(**os.File, *os.File) (*redirection, error)
+	}{
+		{"stdout", &os.Stdout, redirectStream},
+		{"stderr", &os.Stderr, redirectStderr},
+	} {
+		if channelUsesStream(context.Channel, *stream.file) {
+			continue
+		}
+		captured, err := captureStream(context, stream.name, stream.file, stream.redirect)
+		if err != nil {
+			return nil, errors.Join(err, forwarder.Stop())
+		}
+		forwarder.streams = append(forwarder.streams, captured)
+	}
+
+	context.mu.Lock()
+	context.forwarders = append(context.forwarders, forwarder)
+	context.mu.Unlock()
+	return forwarder, nil
+}
+
+// Stop restores the original streams and waits until all captured output has
+// been forwarded.
+func (forwarder *ExternalStreamForwarder) Stop() error {
+	forwarder.stopOnce.Do(func() {
+		var errs []error
+		for _, stream := range forwarder.streams {
+			errs = append(errs, stream.redirection.restore(), stream.writer.Close())
+			<-stream.done
+			errs = append(errs, stream.reader.Close(), stream.redirection.release())
+		}
+		forwarder.stopErr = errors.Join(errs...)
+
+		activeForwarderMu.Lock()
+		if activeForwarder == forwarder {
+			activeForwarder = nil
+		}
+		activeForwarderMu.Unlock()
+	})
+	return forwarder.stopErr
+}
+
+func captureStream(
+	context *PipesContext,
+	name string,
+	file **os.File,
+	redirect func(**os.File, *os.File) (*redirection, error),
+) (*externalStream, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	redirection, err := redirect(file, writer)
+	if err != nil {
+		reader.Close()
+		writer.Close()
+		return nil, err
+	}
+
+	stream := &externalStream{
+		name:        name,
+		reader:      reader,
+		writer:      writer,
+		redirection: redirection,
+		done:        make(chan struct{}),
+	}
+	go stream.pump(context, redirection.original)
+	return stream, nil
+}
+
+// replaceStream replaces *file with writer. Output written to the
+// underlying file descriptor by other means is not captured.
+func replaceStream(file **os.File, writer *os.File) (*redirection, error) {
+	original := *file
+	*file = writer
+
+	return &redirection{
+		original: original,
+		restore: func() error {
+			*file = original
+			return nil
+		},
+		release: func() error {
+			return nil
+		},
+	}, nil
+}
+
+// pump copies captured output to original and forwards it to Dagster until
+// the pipe is closed.
+func (stream *externalStream) pump(context *PipesContext, original io.Writer) {
+	defer close(stream.done)
+
+	buf := make([]byte, externalStreamChunkSize)
+	for {
+		n, err := stream.reader.Read(buf)
+		if n > 0 {
+			original.Write(buf[:n])
+			context.write(types.NewMessage(types.LogExternalStream, map[string]any{
+				"stream": stream.name,
+				"text":   string(buf[:n]),
+				"extras": map[string]any{},
+			}))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// channelUsesStream reports whether channel writes its messages to file.
+func channelUsesStream(channel MessageWriterChannel, file *os.File) bool {
+	switch channel := channel.(type) {
+	case *StreamChannel:
+		return channel.Stream == io.Writer(file)
+	case *BufferedStreamChannel:
+		return channel.Stream == io.Writer(file)
+	case *AsyncChannel:
+		return channelUsesStream(channel.channel, file)
+	default:
+		return false
+	}
+}
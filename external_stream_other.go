@@ -0,0 +1,13 @@
+//go:build !unix
+
+package dagster_pipes
+
+import (
+	"os"
+)
+
+// redirectStream replaces *file with writer, as the file descriptors cannot
+// be redirected portably.
+func redirectStream(file **os.File, writer *os.File) (*redirection, error) {
+	return replaceStream(file, writer)
+}
@@ -0,0 +1,83 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// TestForwardExternalStreams redirects the process streams, so it must not
+// run in parallel with other tests.
+func TestForwardExternalStreams(t *testing.T) {
+	file, context := singleAssetFileAndContext(t)
+
+	forwarder, err := context.ForwardExternalStreams(nil)
+	require.NoError(t, err)
+
+	fmt.Fprintln(os.Stderr, "forwarded by TestForwardExternalStreams")
+
+	err = forwarder.Stop()
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(bytes.TrimSpace(content), &message)
+	require.NoError(t, err)
+	require.Equal(t, &types.PipesMessage{
+		DagsterPipesVersion: "0.1",
+		Method:              types.LogExternalStream,
+		Params: map[string]any{
+			"stream": "stderr",
+			"text":   "forwarded by TestForwardExternalStreams\n",
+			"extras": map[string]any{},
+		},
+	}, &message)
+}
+
+func TestForwardExternalStreams_SkipsMessageStream(t *testing.T) {
+	context := &PipesContext{
+		Channel: NewStreamChannel(os.Stdout),
+		Data:    &types.PipesContextData{},
+	}
+
+	forwarder, err := context.ForwardExternalStreams(nil)
+	require.NoError(t, err)
+	require.Len(t, forwarder.streams, 1)
+	require.Equal(t, "stderr", forwarder.streams[0].name)
+
+	err = context.Close(nil)
+	require.NoError(t, err)
+}
+
+// TestForwardExternalStreams_RefusesSecondForwarder redirects the process
+// streams, so it must not run in parallel with other tests.
+func TestForwardExternalStreams_RefusesSecondForwarder(t *testing.T) {
+	_, context := singleAssetFileAndContext(t)
+
+	forwarder, err := context.ForwardExternalStreams(nil)
+	require.NoError(t, err)
+	_, err = context.ForwardExternalStreams(nil)
+	require.ErrorIs(t, err, ErrExternalStreamsForwarded)
+
+	err = forwarder.Stop()
+	require.NoError(t, err)
+	_, err = context.ForwardExternalStreams(nil)
+	require.NoError(t, err)
+
+	closed := make(chan error)
+	go func() { closed <- context.Close(nil) }()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package dagster_pipes
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirectStream points the file descriptor of *file at writer, so that
+// output written to the descriptor by any code, not only through *file,
+// ends up in writer.
+func redirectStream(file **os.File, writer *os.File) (*redirection, error) {
+	fd := int((*file).Fd())
+
+	originalFd, err := unix.Dup(fd)
+	if err != nil {
+		return nil, err
+	}
+	unix.CloseOnExec(originalFd)
+	original := os.NewFile(uintptr(originalFd), (*file).Name())
+
+	if err := unix.Dup2(int(writer.Fd()), fd); err != nil {
+		original.Close()
+		return nil, err
+	}
+
+	return &redirection{
+		original: original,
+		restore: func() error {
+			return unix.Dup2(originalFd, fd)
+		},
+		release: original.Close,
+	}, nil
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package dagster_pipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+	"golang.org/x/sys/unix"
+)
+
+// TestForwardExternalStreams_KeepsStderrDescriptor redirects the process
+// streams, so it must not run in parallel with other tests.
+func TestForwardExternalStreams_KeepsStderrDescriptor(t *testing.T) {
+	file, context := singleAssetFileAndContext(t)
+
+	forwarder, err := context.ForwardExternalStreams(nil)
+	require.NoError(t, err)
+
+	// The Go runtime writes panics and fatal errors straight to fd 2.
+	_, err = unix.Write(2, []byte("written to fd 2 by TestForwardExternalStreams_KeepsStderrDescriptor\n"))
+	require.NoError(t, err)
+
+	err = forwarder.Stop()
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+	require.Empty(t, content)
+}
+
+// TestForwardExternalStreams_CapturesStderrDescriptor redirects the process
+// streams, so it must not run in parallel with other tests.
+func TestForwardExternalStreams_CapturesStderrDescriptor(t *testing.T) {
+	file, context := singleAssetFileAndContext(t)
+	stderr := os.Stderr
+
+	forwarder, err := context.ForwardExternalStreams(&ExternalStreamOptions{CaptureStderrDescriptor: true})
+	require.NoError(t, err)
+	require.Same(t, stderr, os.Stderr)
+
+	_, err = unix.Write(2, []byte("written to fd 2 by TestForwardExternalStreams_CapturesStderrDescriptor\n"))
+	require.NoError(t, err)
+
+	err = forwarder.Stop()
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(bytes.TrimSpace(content), &message)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"stream": "stderr",
+		"text":   "written to fd 2 by TestForwardExternalStreams_CapturesStderrDescriptor\n",
+		"extras": map[string]any{},
+	}, message.Params)
+}
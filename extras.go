@@ -0,0 +1,234 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrExtraNotFound is wrapped by the ExtraError returned when an extra is
+// missing.
+var ErrExtraNotFound = errors.New("not found")
+
+// ExtraError reports a missing or invalid extra.
+type ExtraError struct {
+	// Key is the key of the offending extra.
+	Key string
+	Err error
+}
+
+func (e *ExtraError) Error() string {
+	return fmt.Sprintf("extras %q: %s", e.Key, e.Err)
+}
+
+func (e *ExtraError) Unwrap() error {
+	return e.Err
+}
+
+// GetExtra returns the extra stored under key, converted to T.
+//
+// Values are converted the way encoding/json would decode them, so numbers
+// can be read as any numeric type and objects as structs. A time.Duration is
+// read from a duration string such as "1h30m" or a number of seconds. An
+// extra that is null counts as missing, as in DecodeExtras.
+//
+//	batchSize, err := dagster_pipes.GetExtra[int](context, "batch_size")
+func GetExtra[T any](context *PipesContext, key string) (T, error) {
+	var result T
+	value := context.Data.Extras[key]
+	if value == nil {
+		return result, &ExtraError{Key: key, Err: ErrExtraNotFound}
+	}
+	if err := decodeExtra(value, reflect.ValueOf(&result).Elem()); err != nil {
+		return result, &ExtraError{Key: key, Err: err}
+	}
+	return result, nil
+}
+
+// GetExtraOr is like GetExtra but returns fallback when the extra is missing.
+// An extra that cannot be converted to T is still an error.
+func GetExtraOr[T any](context *PipesContext, key string, fallback T) (T, error) {
+	result, err := GetExtra[T](context, key)
+	if errors.Is(err, ErrExtraNotFound) {
+		return fallback, nil
+	}
+	return result, err
+}
+
+// DecodeExtras decodes the extras into the struct pointed to by v.
+//
+// Each exported field is read from the extra named by its "extras" tag,
+// falling back to its "json" tag and then to the field name. The tag options
+// "required" and "default=<value>" make a missing extra an error or give it
+// a default value; "-" skips the field, as does a "json" tag of "-". An extra
+// that is null counts as missing:
+//
+//	var config struct {
+//	    Table     string        `extras:"table,required"`
+//	    BatchSize int           `extras:"batch_size,default=500"`
+//	    Timeout   time.Duration `extras:"timeout,default=5m"`
+//	}
+//	if err := context.DecodeExtras(&config); err != nil {
+//	    return err
+//	}
+//
+// Every missing or invalid extra is reported as an ExtraError.
+func (context *PipesContext) DecodeExtras(v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode extras: want a non-nil pointer to a struct, got %T", v)
+	}
+	target = target.Elem()
+
+	var errs []error
+	for i := range target.NumField() {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := parseExtraTag(field)
+		if tag.skip {
+			continue
+		}
+
+		if value := context.Data.Extras[tag.key]; value != nil {
+			if err := decodeExtra(value, target.Field(i)); err != nil {
+				errs = append(errs, &ExtraError{Key: tag.key, Err: err})
+			}
+			continue
+		}
+
+		switch {
+		case tag.hasDefault:
+			if err := decodeExtraDefault(tag.defaultValue, target.Field(i)); err != nil {
+				errs = append(errs, &ExtraError{Key: tag.key, Err: fmt.Errorf("invalid default: %w", err)})
+			}
+		case tag.required:
+			errs = append(errs, &ExtraError{Key: tag.key, Err: ErrExtraNotFound})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type extraTag struct {
+	key          string
+	skip         bool
+	required     bool
+	hasDefault   bool
+	defaultValue string
+}
+
+func parseExtraTag(field reflect.StructField) extraTag {
+	tag := extraTag{key: field.Name}
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "-" {
+		// Like encoding/json, "-," names the extra "-" instead.
+		tag.skip = true
+	} else if name, _, _ := strings.Cut(jsonTag, ","); name != "" {
+		tag.key = name
+	}
+
+	value, ok := field.Tag.Lookup("extras")
+	if !ok {
+		return tag
+	}
+	if value == "-" {
+		tag.skip = true
+		return tag
+	}
+	// An extras tag takes precedence over a json tag that skips the field.
+	tag.skip = false
+
+	name, options, _ := strings.Cut(value, ",")
+	if name != "" {
+		tag.key = name
+	}
+	for options != "" {
+		var option string
+		if strings.HasPrefix(options, "default=") {
+			// The default takes the rest of the tag, so it may contain commas.
+			option, options = options, ""
+		} else {
+			option, options, _ = strings.Cut(options, ",")
+		}
+		switch {
+		case option == "required":
+			tag.required = true
+		case strings.HasPrefix(option, "default="):
+			tag.hasDefault = true
+			tag.defaultValue = strings.TrimPrefix(option, "default=")
+		}
+	}
+	return tag
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// decodeExtra stores the extra value in target.
+func decodeExtra(value any, target reflect.Value) error {
+	if target.Type() == durationType {
+		duration, ok, err := extraDuration(value)
+		if err != nil {
+			return err
+		}
+		if ok {
+			target.SetInt(int64(duration))
+			return nil
+		}
+	}
+
+	if value != nil && reflect.TypeOf(value).AssignableTo(target.Type()) {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target.Addr().Interface())
+}
+
+// extraDuration reads a duration string or a number of seconds. It reports
+// false when value is neither.
+func extraDuration(value any) (time.Duration, bool, error) {
+	switch value := value.(type) {
+	case time.Duration:
+		return value, true, nil
+	case string:
+		duration, err := time.ParseDuration(value)
+		return duration, err == nil, err
+	case json.Number:
+		seconds, err := value.Float64()
+		return time.Duration(seconds * float64(time.Second)), err == nil, err
+	}
+
+	number := reflect.ValueOf(value)
+	switch {
+	case number.CanInt():
+		return time.Duration(number.Int()) * time.Second, true, nil
+	case number.CanUint():
+		return time.Duration(number.Uint()) * time.Second, true, nil
+	case number.CanFloat():
+		return time.Duration(number.Float() * float64(time.Second)), true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// decodeExtraDefault stores the default value written in a struct tag in
+// target. Strings are taken as is, other values are parsed as JSON.
+func decodeExtraDefault(value string, target reflect.Value) error {
+	switch {
+	case target.Kind() == reflect.String:
+		target.SetString(value)
+		return nil
+	case target.Type() == durationType:
+		return decodeExtra(value, target)
+	default:
+		return json.Unmarshal([]byte(value), target.Addr().Interface())
+	}
+}
@@ -0,0 +1,210 @@
+package dagster_pipes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func extrasContext(extras map[string]any) *PipesContext {
+	return &PipesContext{Data: &types.PipesContextData{Extras: extras}}
+}
+
+func TestGetExtra(t *testing.T) {
+	t.Parallel()
+	context := extrasContext(map[string]any{
+		"table":      "users",
+		"batch_size": float64(500),
+		"ratio":      float64(0.5),
+		"timeout":    "5m",
+		"tags":       []any{"a", "b"},
+		"target":     map[string]any{"schema": "public", "table": "users"},
+		"null":       nil,
+	})
+
+	t.Run("converts values", func(t *testing.T) {
+		t.Parallel()
+		table, err := GetExtra[string](context, "table")
+		require.NoError(t, err)
+		require.Equal(t, "users", table)
+
+		batchSize, err := GetExtra[int](context, "batch_size")
+		require.NoError(t, err)
+		require.Equal(t, 500, batchSize)
+
+		timeout, err := GetExtra[time.Duration](context, "timeout")
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, timeout)
+
+		tags, err := GetExtra[[]string](context, "tags")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, tags)
+
+		target, err := GetExtra[struct {
+			Schema string `json:"schema"`
+			Table  string `json:"table"`
+		}](context, "target")
+		require.NoError(t, err)
+		require.Equal(t, "public", target.Schema)
+	})
+
+	t.Run("reads numbers as seconds", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"float":  float64(90),
+			"int":    30,
+			"number": json.Number("1.5"),
+		})
+
+		timeout, err := GetExtra[time.Duration](context, "float")
+		require.NoError(t, err)
+		require.Equal(t, 90*time.Second, timeout)
+
+		timeout, err = GetExtra[time.Duration](context, "int")
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, timeout)
+
+		timeout, err = GetExtra[time.Duration](context, "number")
+		require.NoError(t, err)
+		require.Equal(t, 1500*time.Millisecond, timeout)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetExtra[string](context, "missing")
+		require.ErrorIs(t, err, ErrExtraNotFound)
+
+		var extraErr *ExtraError
+		require.ErrorAs(t, err, &extraErr)
+		require.Equal(t, "missing", extraErr.Key)
+
+		_, err = GetExtra[int](context, "null")
+		require.ErrorIs(t, err, ErrExtraNotFound)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		t.Parallel()
+		_, err := GetExtra[int](context, "ratio")
+		require.ErrorContains(t, err, `extras "ratio"`)
+		require.NotErrorIs(t, err, ErrExtraNotFound)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		t.Parallel()
+		value, err := GetExtraOr(context, "missing", "fallback")
+		require.NoError(t, err)
+		require.Equal(t, "fallback", value)
+
+		value, err = GetExtraOr(context, "table", "fallback")
+		require.NoError(t, err)
+		require.Equal(t, "users", value)
+
+		number, err := GetExtraOr(context, "null", 42)
+		require.NoError(t, err)
+		require.Equal(t, 42, number)
+
+		_, err = GetExtraOr(context, "table", 0)
+		require.Error(t, err)
+	})
+}
+
+func TestPipesContext_DecodeExtras(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Table     string        `extras:"table,required"`
+		BatchSize int           `json:"batch_size"`
+		Timeout   time.Duration `extras:"timeout,default=5m"`
+		Columns   []string      `extras:"columns,default=[\"id\",\"name\"]"`
+		Ignored   string        `extras:"-"`
+	}
+
+	t.Run("decodes with defaults", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"table":      "users",
+			"batch_size": float64(100),
+			"Ignored":    "value",
+		})
+
+		var cfg config
+		err := context.DecodeExtras(&cfg)
+		require.NoError(t, err)
+		require.Equal(t, config{
+			Table:     "users",
+			BatchSize: 100,
+			Timeout:   5 * time.Minute,
+			Columns:   []string{"id", "name"},
+		}, cfg)
+	})
+
+	t.Run("reports every offending key", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"batch_size": "many",
+		})
+
+		var cfg config
+		err := context.DecodeExtras(&cfg)
+		require.ErrorIs(t, err, ErrExtraNotFound)
+		require.ErrorContains(t, err, `extras "table": not found`)
+		require.ErrorContains(t, err, `extras "batch_size"`)
+	})
+
+	t.Run("treats null as missing", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"table":   nil,
+			"timeout": nil,
+		})
+
+		var cfg config
+		err := context.DecodeExtras(&cfg)
+		require.ErrorIs(t, err, ErrExtraNotFound)
+		require.ErrorContains(t, err, `extras "table": not found`)
+		require.Equal(t, 5*time.Minute, cfg.Timeout)
+	})
+
+	t.Run("skips fields tagged json:\"-\"", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"-":       "value",
+			"Skipped": "value",
+			"dash":    "value",
+		})
+
+		var cfg struct {
+			Skipped  string `json:"-"`
+			Dash     string `json:"-,"`
+			Override string `json:"-" extras:"dash"`
+		}
+		err := context.DecodeExtras(&cfg)
+		require.NoError(t, err)
+		require.Empty(t, cfg.Skipped)
+		require.Equal(t, "value", cfg.Dash)
+		require.Equal(t, "value", cfg.Override)
+	})
+
+	t.Run("reads integer durations as seconds", func(t *testing.T) {
+		t.Parallel()
+		context := extrasContext(map[string]any{
+			"table":   "users",
+			"timeout": 30,
+		})
+
+		var cfg config
+		err := context.DecodeExtras(&cfg)
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, cfg.Timeout)
+	})
+
+	t.Run("rejects non-struct targets", func(t *testing.T) {
+		t.Parallel()
+		var value int
+		err := extrasContext(nil).DecodeExtras(&value)
+		require.Error(t, err)
+	})
+}
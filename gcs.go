@@ -0,0 +1,99 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// GCSClient is the subset of a Google Cloud Storage client used by the GCS
+// message writer and context loader.
+//
+// The package does not depend on the Google Cloud SDK. Wrap a
+// storage.Client of cloud.google.com/go/storage to satisfy it:
+//
+//	type gcsClient struct{ client *storage.Client }
+//
+//	func (c gcsClient) ReadObject(ctx context.Context, bucket, object string) (io.ReadCloser, error) {
+//	    return c.client.Bucket(bucket).Object(object).NewReader(ctx)
+//	}
+//
+//	func (c gcsClient) WriteObject(ctx context.Context, bucket, object string, data []byte) error {
+//	    w := c.client.Bucket(bucket).Object(object).NewWriter(ctx)
+//	    if _, err := w.Write(data); err != nil {
+//	        w.Close()
+//	        return err
+//	    }
+//	    return w.Close()
+//	}
+//
+// Creating that client with a custom endpoint makes it work with a fake GCS
+// server.
+type GCSClient interface {
+	ReadObject(ctx context.Context, bucket, object string) (io.ReadCloser, error)
+	WriteObject(ctx context.Context, bucket, object string, data []byte) error
+}
+
+// NewGCSMessageWriter creates a MessageWriter that uploads message chunks to
+// GCS. It reads the "bucket" and "key_prefix" message params and stores
+// chunks as "<key_prefix>/<index>.json", the layout PipesGCSMessageReader
+// expects.
+func NewGCSMessageWriter(client GCSClient) *BlobStoreMessageWriter {
+	return NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		bucket, err := payloadParam(params, "bucket")
+		if err != nil {
+			return nil, err
+		}
+		keyPrefix, err := payloadParam(params, "key_prefix")
+		if err != nil {
+			return nil, err
+		}
+		return &gcsChunkUploader{client: client, bucket: bucket, keyPrefix: keyPrefix}, nil
+	})
+}
+
+type gcsChunkUploader struct {
+	client    GCSClient
+	bucket    string
+	keyPrefix string
+}
+
+func (uploader *gcsChunkUploader) UploadChunk(ctx context.Context, index int, chunk []byte) error {
+	return uploader.client.WriteObject(ctx, uploader.bucket, chunkKey(uploader.keyPrefix, index), chunk)
+}
+
+// GCSContextLoader loads the context data from the GCS object named by the
+// "bucket" and "key" context params, as written by PipesGCSContextInjector.
+type GCSContextLoader struct {
+	Client GCSClient
+}
+
+func NewGCSContextLoader(client GCSClient) *GCSContextLoader {
+	return &GCSContextLoader{Client: client}
+}
+
+func (loader *GCSContextLoader) LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	return loader.LoadContextContext(context.Background(), params)
+}
+
+// LoadContextContext is like LoadContext but gives up when ctx is done.
+func (loader *GCSContextLoader) LoadContextContext(ctx context.Context, params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	bucket, err := payloadParam(params, "bucket")
+	if err != nil {
+		return nil, err
+	}
+	key, err := payloadParam(params, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := loader.Client.ReadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, &PayloadError{Kind: PayloadErrorKind_Unreadable, Err: err}
+	}
+	defer r.Close()
+
+	return decodeContextData(r)
+}
@@ -2,7 +2,10 @@ module github.com/wingyplus/dagster-pipes-go
 
 go 1.24.7
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/stretchr/testify v1.11.1
+	golang.org/x/sys v0.30.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
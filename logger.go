@@ -0,0 +1,66 @@
+package dagster_pipes
+
+import (
+	"context"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// PipesLogger sends log messages to Dagster. They show up in the event log
+// of the Dagster run.
+//
+// PipesContext.Log returns the logger of a context:
+//
+//	context.Log().Info("processing started")
+//	context.Log().Warning("input is empty, skipping")
+type PipesLogger struct {
+	context *PipesContext
+}
+
+// NewPipesLogger creates a PipesLogger that writes through context.
+func NewPipesLogger(context *PipesContext) *PipesLogger {
+	return &PipesLogger{context: context}
+}
+
+// Log sends message to Dagster at the given level.
+func (logger *PipesLogger) Log(level types.PipesLogLevel, message string) error {
+	return logger.context.write(logMessage(level, message))
+}
+
+// LogContext is like Log but gives up when ctx is done.
+func (logger *PipesLogger) LogContext(ctx context.Context, level types.PipesLogLevel, message string) error {
+	return logger.context.writeContext(ctx, logMessage(level, message))
+}
+
+func logMessage(level types.PipesLogLevel, message string) *types.PipesMessage {
+	var params = map[string]any{
+		"message": message,
+		"level":   level,
+	}
+	return types.NewMessage(types.Log, params)
+}
+
+// Debug sends message at the DEBUG level.
+func (logger *PipesLogger) Debug(message string) error {
+	return logger.Log(types.Debug, message)
+}
+
+// Info sends message at the INFO level.
+func (logger *PipesLogger) Info(message string) error {
+	return logger.Log(types.Info, message)
+}
+
+// Warning sends message at the WARNING level.
+func (logger *PipesLogger) Warning(message string) error {
+	return logger.Log(types.Warning, message)
+}
+
+// Error sends message at the ERROR level.
+func (logger *PipesLogger) Error(message string) error {
+	return logger.Log(types.PipesLogLevelERROR, message)
+}
+
+// Critical sends message at the CRITICAL level.
+func (logger *PipesLogger) Critical(message string) error {
+	return logger.Log(types.Critical, message)
+}
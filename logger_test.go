@@ -0,0 +1,58 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestPipesLogger(t *testing.T) {
+	t.Parallel()
+	file, context := singleAssetFileAndContext(t)
+	logger := NewPipesLogger(context)
+
+	require.NoError(t, logger.Debug("debug message"))
+	require.NoError(t, logger.Info("info message"))
+	require.NoError(t, logger.Warning("warning message"))
+	require.NoError(t, logger.Error("error message"))
+	require.NoError(t, logger.Critical("critical message"))
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 5)
+
+	levels := []string{"DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+	for i, line := range lines {
+		var message types.PipesMessage
+		err = json.Unmarshal(line, &message)
+		require.NoError(t, err)
+		require.Equal(t, types.Log, message.Method)
+		require.Equal(t, levels[i], message.Params["level"])
+	}
+
+	var message types.PipesMessage
+	err = json.Unmarshal(lines[0], &message)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"message": "debug message", "level": "DEBUG"}, message.Params)
+}
+
+func TestPipesContextLog(t *testing.T) {
+	t.Parallel()
+	file, context := singleAssetFileAndContext(t)
+
+	require.Same(t, context.Log(), context.Log())
+	require.NoError(t, context.Log().Info("info message"))
+
+	var message types.PipesMessage
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+	err = json.Unmarshal(content, &message)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"message": "info message", "level": "INFO"}, message.Params)
+}
@@ -2,75 +2,264 @@ package dagster_pipes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/wingyplus/dagster-pipes-go/types"
 )
 
+// MessageWriterChannel sends messages to Dagster.
+//
+// Flush pushes out messages that the channel has buffered. Close flushes the
+// channel and releases its resources; it is called once by PipesContext.Close
+// after the closed message has been written.
 type MessageWriterChannel interface {
 	Write(*types.PipesMessage) error
+	Flush() error
+	Close() error
 }
 
+// BatchWriterChannel is implemented by channels that write several messages
+// more cheaply at once than one by one. AsyncChannel hands its batches to it.
+type BatchWriterChannel interface {
+	WriteBatch([]*types.PipesMessage) error
+}
+
+// FileChannel appends newline-delimited messages to the file at Path.
+//
+// The file is opened on the first write and kept open until the channel is
+// closed, which happens when PipesContext.Close runs. Writes are serialized,
+// so the channel is safe for concurrent use.
 type FileChannel struct {
 	Path string
+	// Sync makes the channel fsync the file after every message.
+	Sync bool
+
+	mu   sync.Mutex
+	file *os.File
 }
 
 func (channel *FileChannel) Write(message *types.PipesMessage) error {
-	f, err := os.OpenFile(channel.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	line, err := encodeMessageLine(message)
+	if err != nil {
+		return err
+	}
+	return channel.writeLines(line)
+}
+
+// WriteBatch appends the messages with a single write, and fsyncs the file
+// once when Sync is set. A message that cannot be encoded is skipped and
+// reported in the returned error.
+func (channel *FileChannel) WriteBatch(messages []*types.PipesMessage) error {
+	lines, encodeErr := encodeMessageLines(messages)
+	if len(lines) == 0 {
+		return encodeErr
+	}
+	return errors.Join(encodeErr, channel.writeLines(lines))
+}
+
+func (channel *FileChannel) writeLines(lines []byte) error {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	if channel.file == nil {
+		f, err := os.OpenFile(channel.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		channel.file = f
+	}
+
+	if _, err := channel.file.Write(lines); err != nil {
+		return err
+	}
+	if channel.Sync {
+		return channel.file.Sync()
+	}
+	return nil
+}
+
+// Flush commits the written messages to stable storage.
+func (channel *FileChannel) Flush() error {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	if channel.file == nil {
+		return nil
+	}
+	return channel.file.Sync()
+}
+
+// Close releases the file handle. A later write opens the file again.
+func (channel *FileChannel) Close() error {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	if channel.file == nil {
+		return nil
+	}
+	err := channel.file.Close()
+	channel.file = nil
+	return err
+}
+
+// StreamChannel writes newline-delimited messages to a stream, usually
+// os.Stdout or os.Stderr.
+//
+// Each message is encoded up front and handed to the stream in a single
+// Write call, so a message line is never split by output that user code
+// prints to the same stream.
+type StreamChannel struct {
+	Stream io.Writer
+
+	mu sync.Mutex
+}
+
+// NewStreamChannel creates a StreamChannel that writes to stream.
+func NewStreamChannel(stream io.Writer) *StreamChannel {
+	return &StreamChannel{Stream: stream}
+}
+
+func (channel *StreamChannel) Write(message *types.PipesMessage) error {
+	line, err := encodeMessageLine(message)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	if err := json.NewEncoder(f).Encode(message); err != nil {
-		// TODO: map error
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	_, err = channel.Stream.Write(line)
+	return err
+}
+
+// WriteBatch hands the messages to the stream in a single Write call. A
+// message that cannot be encoded is skipped and reported in the returned
+// error.
+func (channel *StreamChannel) WriteBatch(messages []*types.PipesMessage) error {
+	lines, encodeErr := encodeMessageLines(messages)
+	if len(lines) == 0 {
+		return encodeErr
+	}
+
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	_, err := channel.Stream.Write(lines)
+	return errors.Join(encodeErr, err)
+}
+
+// Flush does nothing, messages are written to the stream immediately.
+func (channel *StreamChannel) Flush() error {
+	return nil
+}
+
+// Close does nothing, the stream is owned by the caller.
+func (channel *StreamChannel) Close() error {
+	return nil
+}
+
+// BufferedStreamChannel keeps messages in memory and writes them to a stream
+// as one block when the channel is closed, which happens when
+// PipesContext.Close runs.
+//
+// The channel does not watch for signals itself. Run handles them, and
+// programs that do not use Run call PipesContext.HandleSignals, so that an
+// interrupted process still closes the session, and with it writes the
+// buffered messages.
+type BufferedStreamChannel struct {
+	Stream io.Writer
+
+	mu     sync.Mutex
+	buffer []byte
+}
+
+// NewBufferedStreamChannel creates a BufferedStreamChannel that writes to stream.
+func NewBufferedStreamChannel(stream io.Writer) *BufferedStreamChannel {
+	return &BufferedStreamChannel{Stream: stream}
+}
+
+func (channel *BufferedStreamChannel) Write(message *types.PipesMessage) error {
+	line, err := encodeMessageLine(message)
+	if err != nil {
 		return err
 	}
+
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+	channel.buffer = append(channel.buffer, line...)
 	return nil
 }
 
+// Flush writes all buffered messages to the stream in a single write.
+func (channel *BufferedStreamChannel) Flush() error {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	if len(channel.buffer) == 0 {
+		return nil
+	}
+	_, err := channel.Stream.Write(channel.buffer)
+	channel.buffer = nil
+	return err
+}
+
+// Close flushes the buffered messages.
+func (channel *BufferedStreamChannel) Close() error {
+	return channel.Flush()
+}
+
 type MessageWriter interface {
-	Open(params map[string]json.RawMessage) MessageWriterChannel
+	Open(params map[string]json.RawMessage) (MessageWriterChannel, error)
 	GetOpenedPayload() map[string]any
 	GetOpenedExtras() map[string]any
 }
 
 type DefaultMessageWriter struct {
+	// FileSync makes file channels fsync the file after every message.
+	FileSync bool
 }
 
 func NewDefaultMessageWriter() *DefaultMessageWriter {
 	return &DefaultMessageWriter{}
 }
 
-func (writer *DefaultMessageWriter) Open(params map[string]json.RawMessage) MessageWriterChannel {
+func (writer *DefaultMessageWriter) Open(params map[string]json.RawMessage) (MessageWriterChannel, error) {
 	var (
-		filePathKey = "path"
-		// stdioKey         = "stdio"
-		// bufferedStdioKey = "buffered_stdio"
-		// stderr = "stderr"
-		// stdout = "stdout"
+		filePathKey      = "path"
+		stdioKey         = "stdio"
+		bufferedStdioKey = "buffered_stdio"
 	)
 
 	if value, ok := params[filePathKey]; ok {
 		var path string
 		if err := json.Unmarshal(value, &path); err != nil {
-			panic(fmt.Errorf("cannot unmarshal path: %w", err))
+			return nil, &PayloadError{Kind: PayloadErrorKind_Malformed, Err: fmt.Errorf("cannot unmarshal path: %w", err)}
 		}
-		return &FileChannel{Path: path}
+		return &FileChannel{Path: path, Sync: writer.FileSync}, nil
 	}
 
-	// if stream, ok := params[stdioKey]; ok {
-	// 	// TODO: Stream channel
-	// 	panic("implements me")
-	// }
-	//
-	// if stream, ok := params[bufferedStdioKey]; ok {
-	// 	// TODO: BufferedStream channel
-	// 	panic("implements me")
-	// }
+	if value, ok := params[stdioKey]; ok {
+		stream, err := resolveStdioStream(value)
+		if err != nil {
+			return nil, err
+		}
+		return NewStreamChannel(stream), nil
+	}
 
-	return nil
+	if value, ok := params[bufferedStdioKey]; ok {
+		stream, err := resolveStdioStream(value)
+		if err != nil {
+			return nil, err
+		}
+		return NewBufferedStreamChannel(stream), nil
+	}
+
+	return nil, &PayloadError{
+		Kind: PayloadErrorKind_Missing,
+		Err:  fmt.Errorf("no message destination, expected %q, %q or %q", filePathKey, stdioKey, bufferedStdioKey),
+	}
 }
 
 func (writer *DefaultMessageWriter) GetOpenedPayload() map[string]any {
@@ -82,3 +271,56 @@ func (writer *DefaultMessageWriter) GetOpenedPayload() map[string]any {
 func (writer *DefaultMessageWriter) GetOpenedExtras() map[string]any {
 	return make(map[string]any)
 }
+
+// resolveStdioStream maps the value of a stdio param ("stdout" or "stderr")
+// to the matching standard stream.
+func resolveStdioStream(value json.RawMessage) (*os.File, error) {
+	var (
+		stdout = "stdout"
+		stderr = "stderr"
+	)
+
+	var stream string
+	if err := json.Unmarshal(value, &stream); err != nil {
+		return nil, &PayloadError{Kind: PayloadErrorKind_Malformed, Err: fmt.Errorf("cannot unmarshal stdio stream: %w", err)}
+	}
+	switch stream {
+	case stdout:
+		return os.Stdout, nil
+	case stderr:
+		return os.Stderr, nil
+	default:
+		return nil, &PayloadError{
+			Kind: PayloadErrorKind_Malformed,
+			Err:  fmt.Errorf("unknown stdio stream %q, expected %q or %q", stream, stdout, stderr),
+		}
+	}
+}
+
+// encodeMessageLine encodes message as a single JSON line terminated by
+// a newline.
+func encodeMessageLine(message *types.PipesMessage) ([]byte, error) {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// encodeMessageLines encodes the messages as consecutive JSON lines,
+// skipping and reporting those that cannot be encoded.
+func encodeMessageLines(messages []*types.PipesMessage) ([]byte, error) {
+	var (
+		lines []byte
+		errs  []error
+	)
+	for _, message := range messages {
+		line, err := encodeMessageLine(message)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lines = append(lines, line...)
+	}
+	return lines, errors.Join(errs...)
+}
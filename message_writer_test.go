@@ -1,9 +1,12 @@
 package dagster_pipes
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,14 +33,193 @@ func TestFileChannel(t *testing.T) {
 	require.Nil(t, message.Params)
 }
 
+func TestFileChannel_Concurrent(t *testing.T) {
+	t.Parallel()
+	f, err := os.CreateTemp("", "file_channel*")
+	require.NoError(t, err)
+	defer f.Close()
+
+	channel := &FileChannel{Path: f.Name(), Sync: true}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				err := channel.Write(types.NewMessage(types.ReportCustomMessage, map[string]any{
+					"payload": "some payload",
+				}))
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err = channel.Close()
+	require.NoError(t, err)
+	require.Nil(t, channel.file)
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 200)
+	for _, line := range lines {
+		var message types.PipesMessage
+		err = json.Unmarshal(line, &message)
+		require.NoError(t, err)
+	}
+}
+
+func TestStreamChannel(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+
+	channel := NewStreamChannel(&buf)
+	err := channel.Write(types.NewMessage(types.Opened, nil))
+	require.NoError(t, err)
+	err = channel.Write(types.NewMessage(types.Closed, nil))
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(lines[1], &message)
+	require.NoError(t, err)
+	require.Equal(t, types.Closed, message.Method)
+}
+
+func TestStreamChannel_WriteBatch(t *testing.T) {
+	t.Parallel()
+	stream := &countingWriter{}
+	channel := NewStreamChannel(stream)
+
+	err := channel.WriteBatch([]*types.PipesMessage{
+		types.NewMessage(types.Opened, nil),
+		types.NewMessage(types.ReportCustomMessage, map[string]any{"payload": func() {}}),
+		types.NewMessage(types.Closed, nil),
+	})
+	require.Error(t, err, "unencodable message")
+	require.Equal(t, 1, stream.writes)
+	require.Equal(t, 2, bytes.Count(stream.Bytes(), []byte("\n")))
+}
+
+func TestFileChannel_WriteBatch(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "messages")
+	channel := &FileChannel{Path: path, Sync: true}
+
+	err := channel.WriteBatch([]*types.PipesMessage{
+		types.NewMessage(types.Opened, nil),
+		types.NewMessage(types.Closed, nil),
+	})
+	require.NoError(t, err)
+	require.NoError(t, channel.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, bytes.Count(content, []byte("\n")))
+}
+
+func TestBufferedStreamChannel(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+
+	channel := &BufferedStreamChannel{Stream: &buf}
+	context := &PipesContext{Channel: channel, Data: &types.PipesContextData{}}
+
+	err := channel.Write(types.NewMessage(types.Opened, nil))
+	require.NoError(t, err)
+	err = context.ReportCustomMessage("payload")
+	require.NoError(t, err)
+	require.Zero(t, buf.Len())
+
+	err = context.Close(nil)
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(lines[2], &message)
+	require.NoError(t, err)
+	require.Equal(t, types.Closed, message.Method)
+}
+
 func TestDefaultMessageWriter(t *testing.T) {
 	t.Parallel()
 	t.Run("open with file path key", func(t *testing.T) {
 		t.Parallel()
 		writer := NewDefaultMessageWriter()
-		channel := writer.Open(map[string]json.RawMessage{
+		channel, err := writer.Open(map[string]json.RawMessage{
 			"path": json.RawMessage([]byte(`"tmp/my-file-path"`)),
 		})
+		require.NoError(t, err)
 		require.Equal(t, &FileChannel{Path: "tmp/my-file-path"}, channel)
 	})
+
+	t.Run("open with file sync", func(t *testing.T) {
+		t.Parallel()
+		writer := &DefaultMessageWriter{FileSync: true}
+		channel, err := writer.Open(map[string]json.RawMessage{
+			"path": json.RawMessage([]byte(`"tmp/my-file-path"`)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, &FileChannel{Path: "tmp/my-file-path", Sync: true}, channel)
+	})
+
+	t.Run("open with stdio key", func(t *testing.T) {
+		t.Parallel()
+		writer := NewDefaultMessageWriter()
+
+		channel, err := writer.Open(map[string]json.RawMessage{
+			"stdio": json.RawMessage([]byte(`"stdout"`)),
+		})
+		require.NoError(t, err)
+		require.IsType(t, &StreamChannel{}, channel)
+		require.Same(t, os.Stdout, channel.(*StreamChannel).Stream)
+
+		channel, err = writer.Open(map[string]json.RawMessage{
+			"stdio": json.RawMessage([]byte(`"stderr"`)),
+		})
+		require.NoError(t, err)
+		require.Same(t, os.Stderr, channel.(*StreamChannel).Stream)
+	})
+
+	t.Run("open with buffered stdio key", func(t *testing.T) {
+		t.Parallel()
+		writer := NewDefaultMessageWriter()
+		channel, err := writer.Open(map[string]json.RawMessage{
+			"buffered_stdio": json.RawMessage([]byte(`"stderr"`)),
+		})
+		require.NoError(t, err)
+		require.IsType(t, &BufferedStreamChannel{}, channel)
+		require.Same(t, os.Stderr, channel.(*BufferedStreamChannel).Stream)
+	})
+
+	t.Run("open with unknown stdio stream", func(t *testing.T) {
+		t.Parallel()
+		writer := NewDefaultMessageWriter()
+		_, err := writer.Open(map[string]json.RawMessage{
+			"stdio": json.RawMessage([]byte(`"stdin"`)),
+		})
+		require.ErrorIs(t, err, PayloadErrorKind_Malformed)
+		require.ErrorContains(t, err, "stdin")
+	})
+
+	t.Run("open with invalid path", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewDefaultMessageWriter().Open(map[string]json.RawMessage{
+			"path": json.RawMessage(`1`),
+		})
+		require.ErrorIs(t, err, PayloadErrorKind_Malformed)
+	})
+
+	t.Run("open without destination", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewDefaultMessageWriter().Open(map[string]json.RawMessage{})
+		require.ErrorIs(t, err, PayloadErrorKind_Missing)
+	})
 }
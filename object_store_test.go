@@ -0,0 +1,176 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// memoryObjectStore is an in-memory stand-in for the blob store backends.
+type memoryObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (store *memoryObjectStore) get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	object, ok := store.objects[bucket+"/"+key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(object)), nil
+}
+
+func (store *memoryObjectStore) put(ctx context.Context, bucket, key string, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.objects == nil {
+		store.objects = make(map[string][]byte)
+	}
+	store.objects[bucket+"/"+key] = body
+	return nil
+}
+
+func (store *memoryObjectStore) object(bucket, key string) []byte {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.objects[bucket+"/"+key]
+}
+
+type memoryS3Client struct{ *memoryObjectStore }
+
+func (client memoryS3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return client.get(ctx, bucket, key)
+}
+
+func (client memoryS3Client) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+	return client.put(ctx, bucket, key, body)
+}
+
+type memoryGCSClient struct{ *memoryObjectStore }
+
+func (client memoryGCSClient) ReadObject(ctx context.Context, bucket, object string) (io.ReadCloser, error) {
+	return client.get(ctx, bucket, object)
+}
+
+func (client memoryGCSClient) WriteObject(ctx context.Context, bucket, object string, data []byte) error {
+	return client.put(ctx, bucket, object, data)
+}
+
+type memoryAzureBlobClient struct{ *memoryObjectStore }
+
+func (client memoryAzureBlobClient) DownloadBlob(ctx context.Context, container, blob string) (io.ReadCloser, error) {
+	return client.get(ctx, container, blob)
+}
+
+func (client memoryAzureBlobClient) UploadBlob(ctx context.Context, container, blob string, data []byte) error {
+	return client.put(ctx, container, blob, data)
+}
+
+// objectStoreBackends lists the blob store backends, each creating its
+// message writer and context loader on top of a memoryObjectStore.
+var objectStoreBackends = []struct {
+	name      string
+	newWriter func(*memoryObjectStore) MessageWriter
+	newLoader func(*memoryObjectStore) LoadContext
+}{
+	{
+		name:      "s3",
+		newWriter: func(store *memoryObjectStore) MessageWriter { return NewS3MessageWriter(memoryS3Client{store}) },
+		newLoader: func(store *memoryObjectStore) LoadContext { return NewS3ContextLoader(memoryS3Client{store}) },
+	},
+	{
+		name:      "gcs",
+		newWriter: func(store *memoryObjectStore) MessageWriter { return NewGCSMessageWriter(memoryGCSClient{store}) },
+		newLoader: func(store *memoryObjectStore) LoadContext { return NewGCSContextLoader(memoryGCSClient{store}) },
+	},
+	{
+		name: "azure",
+		newWriter: func(store *memoryObjectStore) MessageWriter {
+			return NewAzureBlobStorageMessageWriter(memoryAzureBlobClient{store})
+		},
+		newLoader: func(store *memoryObjectStore) LoadContext {
+			return NewAzureBlobStorageContextLoader(memoryAzureBlobClient{store})
+		},
+	},
+}
+
+func TestObjectStoreMessageWriter(t *testing.T) {
+	t.Parallel()
+	for _, backend := range objectStoreBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Parallel()
+			store := &memoryObjectStore{}
+			writer := backend.newWriter(store)
+
+			channel, err := writer.Open(map[string]json.RawMessage{
+				"bucket":     json.RawMessage(`"my-bucket"`),
+				"key_prefix": json.RawMessage(`"messages/run"`),
+			})
+			require.NoError(t, err)
+
+			err = channel.Write(types.NewMessage(types.Opened, nil))
+			require.NoError(t, err)
+			err = channel.Close()
+			require.NoError(t, err)
+
+			var message types.PipesMessage
+			err = json.Unmarshal(store.object("my-bucket", "messages/run/1.json"), &message)
+			require.NoError(t, err)
+			require.Equal(t, types.Opened, message.Method)
+		})
+	}
+}
+
+func TestObjectStoreContextLoader(t *testing.T) {
+	t.Parallel()
+	for _, backend := range objectStoreBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Parallel()
+			store := &memoryObjectStore{}
+			err := store.put(t.Context(), "my-bucket", "context.json", []byte(`{"run_id": "012345", "extras": {}}`))
+			require.NoError(t, err)
+			loader := backend.newLoader(store)
+
+			contextData, err := loader.LoadContext(map[string]json.RawMessage{
+				"bucket": json.RawMessage(`"my-bucket"`),
+				"key":    json.RawMessage(`"context.json"`),
+			})
+			require.NoError(t, err)
+			require.Equal(t, &types.PipesContextData{RunID: "012345", Extras: map[string]any{}}, contextData)
+
+			_, err = loader.LoadContext(map[string]json.RawMessage{
+				"bucket": json.RawMessage(`"my-bucket"`),
+			})
+			require.ErrorIs(t, err, PayloadErrorKind_Malformed, "missing key")
+
+			_, err = loader.LoadContext(map[string]json.RawMessage{
+				"bucket": json.RawMessage(`"my-bucket"`),
+				"key":    json.RawMessage(`"missing.json"`),
+			})
+			require.ErrorIs(t, err, PayloadErrorKind_Unreadable, "missing object")
+
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+			_, err = loader.(LoadContextContext).LoadContextContext(ctx, map[string]json.RawMessage{
+				"bucket": json.RawMessage(`"my-bucket"`),
+				"key":    json.RawMessage(`"context.json"`),
+			})
+			require.ErrorIs(t, err, context.Canceled, "cancelled context")
+		})
+	}
+}
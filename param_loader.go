@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 type LoadParams interface {
@@ -15,25 +16,75 @@ type LoadParams interface {
 	LoadMessageParams() (map[string]json.RawMessage, error)
 }
 
+// ParamsError is returned when params cannot be loaded. It matches its
+// Source with errors.Is and wraps the underlying cause, so that a missing
+// param can be told apart from an invalid one:
+//
+//	if errors.Is(err, dagster_pipes.ParamsErrorKind_NotPresent) {
+//	    // not launched by Dagster
+//	}
 type ParamsError struct {
 	Param  string
 	Origin ParamOrigin
 	Source ParamsErrorKind
+	// Err is the cause of an invalid param.
+	Err error
+	// Tried holds the errors of every source a CompositeLoader tried.
+	Tried []*ParamsError
 }
 
 func (e *ParamsError) Error() string {
-	return fmt.Sprintf("param: %s, origin: %s, source: %s", e.Param, e.Origin, e.Source)
+	msg := fmt.Sprintf("origin: %s, source: %s", e.Origin, e.Source)
+	if e.Param != "" {
+		msg = fmt.Sprintf("param: %s, %s", e.Param, msg)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	if len(e.Tried) == 0 {
+		return msg
+	}
+	tried := make([]string, len(e.Tried))
+	for i, err := range e.Tried {
+		tried[i] = err.Error()
+	}
+	return fmt.Sprintf("%s, tried: [%s]", msg, strings.Join(tried, "; "))
+}
+
+func (e *ParamsError) Unwrap() []error {
+	errs := []error{e.Source}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	for _, err := range e.Tried {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 type ParamOrigin string
 
 var (
-	ParamOrigin_CLI    ParamOrigin = "cli"
-	ParamOrigin_EnvVar ParamOrigin = "env var"
+	ParamOrigin_CLI       ParamOrigin = "cli"
+	ParamOrigin_EnvVar    ParamOrigin = "env var"
+	ParamOrigin_DotEnv    ParamOrigin = "dotenv file"
+	ParamOrigin_Static    ParamOrigin = "static"
+	ParamOrigin_Composite ParamOrigin = "composite"
+	ParamOrigin_Unknown   ParamOrigin = "unknown"
 )
 
+// ParamsSource is implemented by loaders that know the origin of the params
+// they load. CompositeLoader uses it to record where each param came from.
+type ParamsSource interface {
+	Origin() ParamOrigin
+}
+
 type ParamsErrorKind string
 
+func (e ParamsErrorKind) Error() string {
+	return string(e)
+}
+
 var (
 	ParamsErrorKind_NotPresent ParamsErrorKind = "not present"
 	ParamsErrorKind_Invalid    ParamsErrorKind = "invalid"
@@ -51,6 +102,10 @@ func NewEnvVarLoader() *EnvVarLoader {
 	return &EnvVarLoader{}
 }
 
+func (loader *EnvVarLoader) Origin() ParamOrigin {
+	return ParamOrigin_EnvVar
+}
+
 func (loader *EnvVarLoader) IsDagsterPipesProcess() bool {
 	_, ok := os.LookupEnv(DAGSTER_PIPES_CONTEXT_ENV_VAR)
 	return ok
@@ -62,15 +117,10 @@ func (loader *EnvVarLoader) LoadContextParams() (map[string]json.RawMessage, err
 		return nil, &ParamsError{
 			Source: ParamsErrorKind_NotPresent,
 			Param:  DAGSTER_PIPES_CONTEXT_ENV_VAR,
-			Origin: ParamOrigin_CLI,
+			Origin: ParamOrigin_EnvVar,
 		}
 	}
-	result, err := DecodeEnvVar(param)
-	if err != nil {
-		// TODO: convert error to ParamsError.
-		return nil, err
-	}
-	return result, nil
+	return decodeParam(param, DAGSTER_PIPES_CONTEXT_ENV_VAR, ParamOrigin_EnvVar)
 }
 
 func (loader *EnvVarLoader) LoadMessageParams() (map[string]json.RawMessage, error) {
@@ -79,31 +129,184 @@ func (loader *EnvVarLoader) LoadMessageParams() (map[string]json.RawMessage, err
 		return nil, &ParamsError{
 			Source: ParamsErrorKind_NotPresent,
 			Param:  DAGSTER_PIPES_MESSAGES_ENV_VAR,
+			Origin: ParamOrigin_EnvVar,
+		}
+	}
+	return decodeParam(param, DAGSTER_PIPES_MESSAGES_ENV_VAR, ParamOrigin_EnvVar)
+}
+
+var (
+	DAGSTER_PIPES_CONTEXT_CLI_ARG  = "--dagster-pipes-context"
+	DAGSTER_PIPES_MESSAGES_CLI_ARG = "--dagster-pipes-messages"
+)
+
+// CLIArgLoader loads params from command-line arguments, for launchers that
+// can pass arguments but not environment variables. The values are encoded
+// the same way as the environment variables read by EnvVarLoader:
+//
+//	./my-binary --dagster-pipes-context <encoded> --dagster-pipes-messages <encoded>
+//
+// The loader only reads the arguments. Use StripCLIArgs to remove them
+// before the application parses its own flags.
+type CLIArgLoader struct {
+	// Args are the arguments to read, without the program name.
+	Args []string
+}
+
+// NewCLIArgLoader returns a CLIArgLoader that reads os.Args.
+func NewCLIArgLoader() *CLIArgLoader {
+	return &CLIArgLoader{Args: os.Args[1:]}
+}
+
+func (loader *CLIArgLoader) Origin() ParamOrigin {
+	return ParamOrigin_CLI
+}
+
+func (loader *CLIArgLoader) IsDagsterPipesProcess() bool {
+	_, ok := lookupCLIArg(loader.Args, DAGSTER_PIPES_CONTEXT_CLI_ARG)
+	return ok
+}
+
+func (loader *CLIArgLoader) LoadContextParams() (map[string]json.RawMessage, error) {
+	return loader.load(DAGSTER_PIPES_CONTEXT_CLI_ARG)
+}
+
+func (loader *CLIArgLoader) LoadMessageParams() (map[string]json.RawMessage, error) {
+	return loader.load(DAGSTER_PIPES_MESSAGES_CLI_ARG)
+}
+
+func (loader *CLIArgLoader) load(name string) (map[string]json.RawMessage, error) {
+	param, ok := lookupCLIArg(loader.Args, name)
+	if !ok {
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_NotPresent,
+			Param:  name,
 			Origin: ParamOrigin_CLI,
 		}
 	}
-	result, err := DecodeEnvVar(param)
+	return decodeParam(param, name, ParamOrigin_CLI)
+}
+
+// StripCLIArgs returns a copy of args without the arguments read by
+// CLIArgLoader, so that they do not upset the application's own flag
+// parsing:
+//
+//	flag.CommandLine.Parse(dagster_pipes.StripCLIArgs(os.Args[1:]))
+func StripCLIArgs(args []string) []string {
+	stripped := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return append(stripped, args[i:]...)
+		}
+		name, _, hasValue := strings.Cut(args[i], "=")
+		if !isPipesCLIArg(name) {
+			stripped = append(stripped, args[i])
+			continue
+		}
+		if !hasValue {
+			i++
+		}
+	}
+	return stripped
+}
+
+// lookupCLIArg finds the value of the argument name, given either as
+// "--name value" or "--name=value". Arguments after "--" are not flags.
+func lookupCLIArg(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func isPipesCLIArg(name string) bool {
+	return name == DAGSTER_PIPES_CONTEXT_CLI_ARG || name == DAGSTER_PIPES_MESSAGES_CLI_ARG
+}
+
+// DecodeEnvVar decodes a param encoded by Dagster: base64-encoded,
+// zlib-compressed JSON. A param that cannot be decoded is reported as a
+// ParamsError of kind ParamsErrorKind_Invalid.
+func DecodeEnvVar(param string) (map[string]json.RawMessage, error) {
+	result, err := decodeEnvVar(param)
 	if err != nil {
-		// TODO: convert error to ParamsError.
-		return nil, err
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_Invalid,
+			Origin: ParamOrigin_Unknown,
+			Err:    err,
+		}
 	}
 	return result, nil
 }
 
-func DecodeEnvVar(param string) (map[string]json.RawMessage, error) {
+func decodeEnvVar(param string) (map[string]json.RawMessage, error) {
 	zlibCompressedBytes, err := base64.StdEncoding.DecodeString(param)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot decode base64: %w", err)
 	}
 
 	r, err := zlib.NewReader(bytes.NewBuffer(zlibCompressedBytes))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot decompress zlib: %w", err)
 	}
 
 	var result map[string]json.RawMessage
 	if err := json.NewDecoder(r).Decode(&result); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot decode JSON: %w", err)
+	}
+	return result, nil
+}
+
+// EncodeEnvVar encodes params the way Dagster does: JSON, compressed with
+// zlib and encoded with base64. It is the counterpart of DecodeEnvVar.
+func EncodeEnvVar(params any) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", fmt.Errorf("cannot compress zlib: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("cannot compress zlib: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeParam decodes the value of the param name read from origin,
+// reporting a failure as an invalid param.
+func decodeParam(value string, name string, origin ParamOrigin) (map[string]json.RawMessage, error) {
+	result, err := decodeEnvVar(value)
+	if err != nil {
+		return nil, &ParamsError{
+			Source: ParamsErrorKind_Invalid,
+			Param:  name,
+			Origin: origin,
+			Err:    err,
+		}
 	}
 	return result, nil
 }
+
+// stringParam reads the string value of key from params.
+func stringParam(params map[string]json.RawMessage, key string) (string, error) {
+	value, ok := params[key]
+	if !ok {
+		return "", fmt.Errorf("missing param %q", key)
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", fmt.Errorf("cannot unmarshal param %q: %w", key, err)
+	}
+	return s, nil
+}
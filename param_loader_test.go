@@ -1,6 +1,7 @@
 package dagster_pipes
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"os"
 	"testing"
@@ -35,3 +36,75 @@ func TestEnvVarLoader(t *testing.T) {
 		require.Equal(t, expected, result)
 	})
 }
+
+func TestCLIArgLoader(t *testing.T) {
+	t.Parallel()
+	encoded := "eJwVwdEJgCAQANBV4ha4SDNsjhYQNf0wFTtEjXaP3nsgK/KwT4BVFTxTMLbc2DakIFwfUS516MJWkrw5En3+uYwH0pWDZzpyW1GnSLYRvB9CZRtp"
+	expected := map[string]json.RawMessage{
+		"path": json.RawMessage([]byte(`"/var/folders/x7/tl6gyzn92vzcr35t94xgt6y00000gp/T/tmplh3cn4ev/context"`)),
+	}
+
+	loader := &CLIArgLoader{Args: []string{
+		"-v",
+		DAGSTER_PIPES_CONTEXT_CLI_ARG, encoded,
+		DAGSTER_PIPES_MESSAGES_CLI_ARG + "=" + encoded,
+		"input.csv",
+	}}
+	require.True(t, loader.IsDagsterPipesProcess())
+
+	result, err := loader.LoadContextParams()
+	require.NoError(t, err)
+	require.Equal(t, expected, result)
+
+	result, err = loader.LoadMessageParams()
+	require.NoError(t, err)
+	require.Equal(t, expected, result)
+
+	require.Equal(t, []string{"-v", "input.csv"}, StripCLIArgs(loader.Args))
+	require.Len(t, loader.Args, 5)
+}
+
+func TestCLIArgLoader_NotPresent(t *testing.T) {
+	t.Parallel()
+	loader := &CLIArgLoader{Args: []string{"--", DAGSTER_PIPES_CONTEXT_CLI_ARG, "value"}}
+	require.False(t, loader.IsDagsterPipesProcess())
+
+	_, err := loader.LoadContextParams()
+	require.Equal(t, &ParamsError{
+		Param:  DAGSTER_PIPES_CONTEXT_CLI_ARG,
+		Origin: ParamOrigin_CLI,
+		Source: ParamsErrorKind_NotPresent,
+	}, err)
+
+	require.Equal(t, loader.Args, StripCLIArgs(loader.Args))
+}
+
+func TestCLIArgLoader_Invalid(t *testing.T) {
+	t.Parallel()
+	loader := &CLIArgLoader{Args: []string{DAGSTER_PIPES_CONTEXT_CLI_ARG, "not-base64!"}}
+
+	_, err := loader.LoadContextParams()
+	require.ErrorIs(t, err, ParamsErrorKind_Invalid)
+	require.NotErrorIs(t, err, ParamsErrorKind_NotPresent)
+	require.ErrorAs(t, err, new(base64.CorruptInputError))
+
+	var paramsErr *ParamsError
+	require.ErrorAs(t, err, &paramsErr)
+	require.Equal(t, DAGSTER_PIPES_CONTEXT_CLI_ARG, paramsErr.Param)
+	require.Equal(t, ParamOrigin_CLI, paramsErr.Origin)
+}
+
+func TestDecodeEnvVar_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, param := range []string{
+		"not-base64!",
+		base64.StdEncoding.EncodeToString([]byte("not zlib")),
+	} {
+		_, err := DecodeEnvVar(param)
+		require.ErrorIs(t, err, ParamsErrorKind_Invalid)
+
+		var paramsErr *ParamsError
+		require.ErrorAs(t, err, &paramsErr)
+		require.Equal(t, ParamOrigin_Unknown, paramsErr.Origin)
+	}
+}
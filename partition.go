@@ -0,0 +1,159 @@
+package dagster_pipes
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+)
+
+// multiPartitionKeyDelimiter separates the dimension keys of a
+// multi-partition key.
+const multiPartitionKeyDelimiter = "|"
+
+// TimeWindow is the time range of a time-partitioned run. Start is
+// inclusive and End is exclusive.
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// PartitionGranularity is the size of the windows produced by
+// TimeWindow.Split.
+type PartitionGranularity int
+
+const (
+	Hourly PartitionGranularity = iota
+	Daily
+	Monthly
+)
+
+// IsPartitioned reports whether the run targets one or more partitions.
+func (context *PipesContext) IsPartitioned() bool {
+	return context.Data.PartitionKey != nil || context.Data.PartitionKeyRange != nil
+}
+
+// IsPartitionRange reports whether the run targets a range of partitions,
+// as a backfill does, rather than a single partition.
+func (context *PipesContext) IsPartitionRange() bool {
+	keyRange := context.Data.PartitionKeyRange
+	if keyRange == nil || keyRange.Start == nil || keyRange.End == nil {
+		return false
+	}
+	return *keyRange.Start != *keyRange.End
+}
+
+// PartitionTimeWindow parses the time window of a time-partitioned run. It
+// reports false when the run has no time window.
+//
+// Times without a UTC offset are interpreted in loc, and all times are
+// returned in loc. A nil loc means UTC.
+func (context *PipesContext) PartitionTimeWindow(loc *time.Location) (TimeWindow, bool, error) {
+	window := context.Data.PartitionTimeWindow
+	if window == nil || window.Start == nil || window.End == nil {
+		return TimeWindow{}, false, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start, err := parsePartitionTime(*window.Start, loc)
+	if err != nil {
+		return TimeWindow{}, false, fmt.Errorf("partition time window start: %w", err)
+	}
+	end, err := parsePartitionTime(*window.End, loc)
+	if err != nil {
+		return TimeWindow{}, false, fmt.Errorf("partition time window end: %w", err)
+	}
+	return TimeWindow{Start: start, End: end}, true, nil
+}
+
+// MultiPartitionKey splits the partition key of a multi-partitioned run into
+// its dimensions. See ParseMultiPartitionKey.
+func (context *PipesContext) MultiPartitionKey(dimensions ...string) (map[string]string, error) {
+	if context.Data.PartitionKey == nil {
+		return nil, fmt.Errorf("run has no partition key")
+	}
+	return ParseMultiPartitionKey(*context.Data.PartitionKey, dimensions...)
+}
+
+// ParseMultiPartitionKey splits a multi-partition key such as "2024-01-01|us"
+// into a map from dimension name to key.
+//
+// Dagster joins the keys ordered by dimension name, so dimensions may be
+// given in any order:
+//
+//	keys, err := dagster_pipes.ParseMultiPartitionKey("2024-01-01|us", "region", "date")
+//	// keys["date"] == "2024-01-01", keys["region"] == "us"
+func ParseMultiPartitionKey(key string, dimensions ...string) (map[string]string, error) {
+	parts := strings.Split(key, multiPartitionKeyDelimiter)
+	if len(parts) != len(dimensions) {
+		return nil, fmt.Errorf("multi-partition key %q has %d dimensions, want %d", key, len(parts), len(dimensions))
+	}
+
+	sorted := slices.Clone(dimensions)
+	slices.Sort(sorted)
+
+	keys := make(map[string]string, len(sorted))
+	for i, dimension := range sorted {
+		keys[dimension] = parts[i]
+	}
+	return keys, nil
+}
+
+// Split iterates over the hourly, daily or monthly sub-windows of window.
+// Sub-windows are aligned to calendar boundaries in the location of
+// window.Start, and the first and last sub-windows are clipped to window:
+//
+//	for day := range window.Split(dagster_pipes.Daily) {
+//	    process(day.Start, day.End)
+//	}
+func (window TimeWindow) Split(granularity PartitionGranularity) iter.Seq[TimeWindow] {
+	return func(yield func(TimeWindow) bool) {
+		for start := window.Start; start.Before(window.End); {
+			end := nextBoundary(start, granularity)
+			if end.After(window.End) {
+				end = window.End
+			}
+			if !yield(TimeWindow{Start: start, End: end}) {
+				return
+			}
+			start = end
+		}
+	}
+}
+
+// nextBoundary returns the first calendar boundary of granularity after t.
+func nextBoundary(t time.Time, granularity PartitionGranularity) time.Time {
+	year, month, day := t.Date()
+	switch granularity {
+	case Hourly:
+		next := time.Date(year, month, day, t.Hour()+1, 0, 0, 0, t.Location())
+		if !next.After(t) {
+			// The wall clock went back, as at the end of daylight saving time.
+			next = t.Add(time.Hour)
+		}
+		return next
+	case Monthly:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// parsePartitionTime parses a time as serialized by Dagster, which uses
+// ISO 8601 with or without a UTC offset.
+func parsePartitionTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time %q", value)
+}
@@ -0,0 +1,160 @@
+package dagster_pipes
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/internal/helper"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func partitionContext(data *types.PipesContextData) *PipesContext {
+	return &PipesContext{Data: data}
+}
+
+func TestPipesContext_Partitions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unpartitioned", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{})
+		require.False(t, context.IsPartitioned())
+		require.False(t, context.IsPartitionRange())
+
+		_, ok, err := context.PartitionTimeWindow(nil)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("single partition", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{
+			PartitionKey: helper.Ptr("2024-01-01"),
+			PartitionKeyRange: &types.PartitionKeyRange{
+				Start: helper.Ptr("2024-01-01"),
+				End:   helper.Ptr("2024-01-01"),
+			},
+		})
+		require.True(t, context.IsPartitioned())
+		require.False(t, context.IsPartitionRange())
+	})
+
+	t.Run("partition range", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{
+			PartitionKeyRange: &types.PartitionKeyRange{
+				Start: helper.Ptr("2024-01-01"),
+				End:   helper.Ptr("2024-01-03"),
+			},
+		})
+		require.True(t, context.IsPartitioned())
+		require.True(t, context.IsPartitionRange())
+	})
+}
+
+func TestPipesContext_PartitionTimeWindow(t *testing.T) {
+	t.Parallel()
+	bangkok := time.FixedZone("Asia/Bangkok", 7*60*60)
+
+	t.Run("with offset", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{
+			PartitionTimeWindow: &types.PartitionTimeWindow{
+				Start: helper.Ptr("2024-01-01T00:00:00+00:00"),
+				End:   helper.Ptr("2024-01-02T00:00:00+00:00"),
+			},
+		})
+
+		window, ok, err := context.PartitionTimeWindow(bangkok)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.True(t, window.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		require.Equal(t, bangkok, window.Start.Location())
+		require.Equal(t, 7, window.Start.Hour())
+	})
+
+	t.Run("without offset", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{
+			PartitionTimeWindow: &types.PartitionTimeWindow{
+				Start: helper.Ptr("2024-01-01T00:00:00"),
+				End:   helper.Ptr("2024-01-02"),
+			},
+		})
+
+		window, ok, err := context.PartitionTimeWindow(bangkok)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, bangkok), window.Start)
+		require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, bangkok), window.End)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		context := partitionContext(&types.PipesContextData{
+			PartitionTimeWindow: &types.PartitionTimeWindow{
+				Start: helper.Ptr("yesterday"),
+				End:   helper.Ptr("2024-01-02"),
+			},
+		})
+
+		_, _, err := context.PartitionTimeWindow(nil)
+		require.ErrorContains(t, err, "yesterday")
+	})
+}
+
+func TestParseMultiPartitionKey(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseMultiPartitionKey("2024-01-01|us", "region", "date")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"date": "2024-01-01", "region": "us"}, keys)
+
+	_, err = ParseMultiPartitionKey("2024-01-01", "region", "date")
+	require.Error(t, err)
+
+	context := partitionContext(&types.PipesContextData{PartitionKey: helper.Ptr("a|b")})
+	keys, err = context.MultiPartitionKey("x", "y")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"x": "a", "y": "b"}, keys)
+}
+
+func TestTimeWindow_Split(t *testing.T) {
+	t.Parallel()
+
+	t.Run("daily", func(t *testing.T) {
+		t.Parallel()
+		window := TimeWindow{
+			Start: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 3, 6, 0, 0, 0, time.UTC),
+		}
+		days := slices.Collect(window.Split(Daily))
+		require.Equal(t, []TimeWindow{
+			{Start: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 3, 6, 0, 0, 0, time.UTC)},
+		}, days)
+	})
+
+	t.Run("hourly", func(t *testing.T) {
+		t.Parallel()
+		window := TimeWindow{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		}
+		require.Len(t, slices.Collect(window.Split(Hourly)), 24)
+	})
+
+	t.Run("monthly", func(t *testing.T) {
+		t.Parallel()
+		window := TimeWindow{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		months := slices.Collect(window.Split(Monthly))
+		require.Len(t, months, 12)
+		require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), months[1].End)
+	})
+}
@@ -0,0 +1,134 @@
+package dagster_pipes
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"runtime/debug"
+	"slices"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// ErrUnknownAssetKey is wrapped by the error returned when asking for the
+// provenance or code version of an asset that is not part of the run.
+var ErrUnknownAssetKey = errors.New("unknown asset key")
+
+// BinaryCodeVersion is the code version embedded in the binary. Set it at
+// build time with:
+//
+//	go build -ldflags "-X github.com/wingyplus/dagster-pipes-go.BinaryCodeVersion=v1"
+//
+// When it is empty, BuildCodeVersion falls back to the VCS revision recorded
+// by the Go toolchain.
+var BinaryCodeVersion string
+
+// BuildCodeVersion returns the code version of the running binary: the value
+// of BinaryCodeVersion, or else the VCS revision the binary was built from.
+// It returns an empty string when neither is known.
+func BuildCodeVersion() string {
+	if BinaryCodeVersion != "" {
+		return BinaryCodeVersion
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// lookupAssetKey returns assetKey when it is one of the assets of the run.
+// An empty key stands for the only asset of a single-asset run.
+func (context *PipesContext) lookupAssetKey(assetKey string) (string, error) {
+	assetKeys := context.Data.AssetKeys
+	if assetKey == "" {
+		if len(assetKeys) == 1 {
+			return assetKeys[0], nil
+		}
+		return "", ErrMissingAssetKey
+	}
+	if !slices.Contains(assetKeys, assetKey) {
+		return "", fmt.Errorf("%w %q, expected one of %q", ErrUnknownAssetKey, assetKey, assetKeys)
+	}
+	return assetKey, nil
+}
+
+// Provenance returns the provenance of the last materialization of the
+// asset, or nil when the asset has never been materialized. The asset must
+// be one of the assets of the run; an empty key selects the only asset of a
+// single-asset run.
+func (context *PipesContext) Provenance(assetKey string) (*types.ProvenanceByAssetKey, error) {
+	ak, err := context.lookupAssetKey(assetKey)
+	if err != nil {
+		return nil, err
+	}
+	return context.Data.ProvenanceByAssetKey[ak], nil
+}
+
+// InputDataVersions returns the data versions of the upstream assets that
+// the last materialization of the asset was computed from, keyed by
+// upstream asset key.
+func (context *PipesContext) InputDataVersions(assetKey string) (map[string]string, error) {
+	provenance, err := context.Provenance(assetKey)
+	if err != nil || provenance == nil {
+		return nil, err
+	}
+	return provenance.InputDataVersions, nil
+}
+
+// CodeVersion returns the code version Dagster expects for the asset. It
+// reports false when the asset has no code version.
+func (context *PipesContext) CodeVersion(assetKey string) (string, bool, error) {
+	ak, err := context.lookupAssetKey(assetKey)
+	if err != nil {
+		return "", false, err
+	}
+	codeVersion := context.Data.CodeVersionByAssetKey[ak]
+	if codeVersion == nil {
+		return "", false, nil
+	}
+	return *codeVersion, true, nil
+}
+
+// CodeVersionMatches reports whether the code version Dagster expects for
+// the asset is the one returned by BuildCodeVersion. An asset without a code
+// version never matches.
+func (context *PipesContext) CodeVersionMatches(assetKey string) (bool, error) {
+	codeVersion, ok, err := context.CodeVersion(assetKey)
+	if err != nil || !ok {
+		return false, err
+	}
+	return codeVersion == BuildCodeVersion(), nil
+}
+
+// IsStale reports whether the asset must be recomputed. It is stale when it
+// has never been materialized, when its code version changed since the last
+// materialization, or when upstream, the current data versions of its
+// upstream assets, differs from the versions it was last computed from.
+//
+// An asset that is not stale can skip recomputation and report the data
+// version of its last materialization again.
+func (context *PipesContext) IsStale(assetKey string, upstream map[string]string) (bool, error) {
+	provenance, err := context.Provenance(assetKey)
+	if err != nil {
+		return false, err
+	}
+	if provenance == nil {
+		return true, nil
+	}
+
+	codeVersion, ok, err := context.CodeVersion(assetKey)
+	if err != nil {
+		return false, err
+	}
+	if ok && (provenance.CodeVersion == nil || *provenance.CodeVersion != codeVersion) {
+		return true, nil
+	}
+
+	return !maps.Equal(provenance.InputDataVersions, upstream), nil
+}
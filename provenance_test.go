@@ -0,0 +1,129 @@
+package dagster_pipes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/internal/helper"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func provenanceContext() *PipesContext {
+	return &PipesContext{Data: &types.PipesContextData{
+		AssetKeys: []string{"orders", "customers", "reports"},
+		CodeVersionByAssetKey: map[string]*string{
+			"orders":    helper.Ptr("v2"),
+			"customers": helper.Ptr("v1"),
+		},
+		ProvenanceByAssetKey: map[string]*types.ProvenanceByAssetKey{
+			"orders": {
+				CodeVersion:       helper.Ptr("v1"),
+				InputDataVersions: map[string]string{"raw_orders": "a"},
+			},
+			"customers": {
+				CodeVersion:       helper.Ptr("v1"),
+				InputDataVersions: map[string]string{"raw_customers": "b"},
+			},
+		},
+	}}
+}
+
+func TestPipesContext_Provenance(t *testing.T) {
+	t.Parallel()
+	context := provenanceContext()
+
+	versions, err := context.InputDataVersions("customers")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"raw_customers": "b"}, versions)
+
+	versions, err = context.InputDataVersions("reports")
+	require.NoError(t, err)
+	require.Nil(t, versions)
+
+	codeVersion, ok, err := context.CodeVersion("orders")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "v2", codeVersion)
+
+	_, ok, err = context.CodeVersion("reports")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestPipesContext_ProvenanceUnknownAssetKey(t *testing.T) {
+	t.Parallel()
+	context := provenanceContext()
+
+	_, err := context.Provenance("invoices")
+	require.ErrorIs(t, err, ErrUnknownAssetKey)
+
+	_, err = context.InputDataVersions("invoices")
+	require.ErrorIs(t, err, ErrUnknownAssetKey)
+
+	_, _, err = context.CodeVersion("invoices")
+	require.ErrorIs(t, err, ErrUnknownAssetKey)
+
+	_, err = context.IsStale("invoices", nil)
+	require.ErrorIs(t, err, ErrUnknownAssetKey)
+
+	_, err = context.Provenance("")
+	require.ErrorIs(t, err, ErrMissingAssetKey)
+}
+
+func TestPipesContext_ProvenanceSingleAsset(t *testing.T) {
+	t.Parallel()
+	context := &PipesContext{Data: &types.PipesContextData{
+		AssetKeys:             []string{"orders"},
+		CodeVersionByAssetKey: map[string]*string{"orders": helper.Ptr("v2")},
+	}}
+
+	codeVersion, ok, err := context.CodeVersion("")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "v2", codeVersion)
+}
+
+func TestPipesContext_IsStale(t *testing.T) {
+	t.Parallel()
+	context := provenanceContext()
+
+	tests := []struct {
+		name     string
+		assetKey string
+		upstream map[string]string
+		stale    bool
+	}{
+		{"unchanged", "customers", map[string]string{"raw_customers": "b"}, false},
+		{"upstream changed", "customers", map[string]string{"raw_customers": "c"}, true},
+		{"upstream added", "customers", map[string]string{"raw_customers": "b", "raw_regions": "d"}, true},
+		{"code version changed", "orders", map[string]string{"raw_orders": "a"}, true},
+		{"never materialized", "reports", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stale, err := context.IsStale(tt.assetKey, tt.upstream)
+			require.NoError(t, err)
+			require.Equal(t, tt.stale, stale)
+		})
+	}
+}
+
+func TestPipesContext_CodeVersionMatches(t *testing.T) {
+	BinaryCodeVersion = "v2"
+	t.Cleanup(func() { BinaryCodeVersion = "" })
+
+	context := provenanceContext()
+
+	matches, err := context.CodeVersionMatches("orders")
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	matches, err = context.CodeVersionMatches("customers")
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	matches, err = context.CodeVersionMatches("reports")
+	require.NoError(t, err)
+	require.False(t, matches)
+}
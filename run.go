@@ -0,0 +1,72 @@
+package dagster_pipes
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// Run opens a pipes session with OpenDasterPipes, calls fn and closes the
+// session. It takes care of the open, defer Close and recover boilerplate:
+//
+//	func main() {
+//	    dagster_pipes.Run(func(context *dagster_pipes.PipesContext) error {
+//	        return context.ReportAssetMaterialization("my_asset", nil, "v1")
+//	    })
+//	}
+//
+// An error returned by fn, or a panic in fn, is reported to Dagster as a
+// PipesException when the session is closed. The closed message is always
+// written, after which Run exits the process with status 1 if the session
+// could not be opened or closed, or fn failed. Run returns normally when
+// everything succeeded.
+//
+// Run handles SIGINT and SIGTERM with HandleSignals and the default options,
+// so that a cancelled run still writes its buffered messages and the closed
+// message. fn sees the interruption as the cancellation of
+// PipesContext.Context, and may call HandleSignals to use other options.
+//
+// Only panics on the goroutine that calls fn are recovered. Since Run may
+// exit the process, deferred calls of the caller do not run on failure.
+func Run(fn func(*PipesContext) error) {
+	if code := run(OpenDasterPipes, fn); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// run implements Run and returns the exit code instead of exiting.
+func run(open func() (*PipesContext, error), fn func(*PipesContext) error) int {
+	context, err := open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dagster pipes: cannot open session: %v\n", err)
+		return 1
+	}
+
+	stop := context.HandleSignals(nil)
+	defer stop()
+
+	exception := callRecovering(context, fn)
+	if err := context.Close(exception); err != nil {
+		fmt.Fprintf(os.Stderr, "dagster pipes: cannot close session: %v\n", err)
+		return 1
+	}
+	if exception != nil {
+		return 1
+	}
+	return 0
+}
+
+// callRecovering calls fn and converts its error or panic into an exception.
+func callRecovering(context *PipesContext, fn func(*PipesContext) error) (exception *types.PipesException) {
+	defer func() {
+		if value := recover(); value != nil {
+			exception = PipesExceptionPanic(value)
+		}
+	}()
+
+	if err := fn(context); err != nil {
+		return PipesExceptionError(err)
+	}
+	return nil
+}
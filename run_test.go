@@ -0,0 +1,105 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	closedMessage := func(t *testing.T, path string) *types.PipesMessage {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var message types.PipesMessage
+		err = json.Unmarshal(content, &message)
+		require.NoError(t, err)
+		require.Equal(t, types.Closed, message.Method)
+		return &message
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+
+		code := run(func() (*PipesContext, error) { return context, nil }, func(*PipesContext) error {
+			return nil
+		})
+		require.Zero(t, code)
+		require.Nil(t, closedMessage(t, file.Path).Params)
+	})
+
+	t.Run("returned error", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+
+		code := run(func() (*PipesContext, error) { return context, nil }, func(*PipesContext) error {
+			return errors.New("some error")
+		})
+		require.Equal(t, 1, code)
+		require.Equal(t, "some error", closedMessage(t, file.Path).Params["message"])
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+
+		code := run(func() (*PipesContext, error) { return context, nil }, func(*PipesContext) error {
+			panic("boom")
+		})
+		require.Equal(t, 1, code)
+
+		params := closedMessage(t, file.Path).Params
+		require.Equal(t, "boom", params["message"])
+		require.Equal(t, "panic", params["name"])
+		require.NotEmpty(t, params["stack"])
+	})
+
+	t.Run("cannot open", func(t *testing.T) {
+		t.Parallel()
+		called := false
+		code := run(func() (*PipesContext, error) { return nil, errors.New("not launched by dagster") }, func(*PipesContext) error {
+			called = true
+			return nil
+		})
+		require.Equal(t, 1, code)
+		require.False(t, called)
+	})
+}
+
+// TestRun_HandlesSignals sends SIGTERM to the test process, so it must not
+// run in parallel with other tests.
+func TestRun_HandlesSignals(t *testing.T) {
+	file, pipesContext := singleAssetFileAndContext(t)
+
+	code := run(func() (*PipesContext, error) { return pipesContext, nil }, func(pipesContext *PipesContext) error {
+		process, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+		if err := process.Signal(syscall.SIGTERM); err != nil {
+			return err
+		}
+		<-pipesContext.Context().Done()
+		return context.Cause(pipesContext.Context())
+	})
+	require.Equal(t, 1, code)
+
+	content, err := os.ReadFile(file.Path)
+	require.NoError(t, err)
+
+	var message types.PipesMessage
+	err = json.Unmarshal(content, &message)
+	require.NoError(t, err)
+	require.Equal(t, types.Closed, message.Method)
+	require.Equal(t, "interrupted by signal: terminated", message.Params["message"])
+}
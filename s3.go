@@ -0,0 +1,108 @@
+package dagster_pipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// S3Client is the subset of an S3 client used by the S3 message writer and
+// context loader.
+//
+// The package does not depend on the AWS SDK. Wrap an s3.Client of
+// github.com/aws/aws-sdk-go-v2/service/s3 to satisfy it:
+//
+//	type s3Client struct{ client *s3.Client }
+//
+//	func (c s3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+//	    out, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return out.Body, nil
+//	}
+//
+//	func (c s3Client) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+//	    _, err := c.client.PutObject(ctx, &s3.PutObjectInput{Bucket: &bucket, Key: &key, Body: bytes.NewReader(body)})
+//	    return err
+//	}
+//
+// Pointing the wrapped client at a custom endpoint makes it work with
+// S3-compatible stores such as MinIO.
+//
+// The S3 writer and loader are tested against an in-memory S3Client only.
+// The conformance suite does not drive them end to end, since the
+// conformance binary has no SDK-backed client.
+type S3Client interface {
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+}
+
+// NewS3MessageWriter creates a MessageWriter that uploads message chunks to
+// S3. It reads the "bucket" and "key_prefix" message params and stores chunks
+// as "<key_prefix>/<index>.json", the layout PipesS3MessageReader expects.
+func NewS3MessageWriter(client S3Client) *BlobStoreMessageWriter {
+	return NewBlobStoreMessageWriter(func(params map[string]json.RawMessage) (ChunkUploader, error) {
+		bucket, err := payloadParam(params, "bucket")
+		if err != nil {
+			return nil, err
+		}
+		keyPrefix, err := payloadParam(params, "key_prefix")
+		if err != nil {
+			return nil, err
+		}
+		return &s3ChunkUploader{client: client, bucket: bucket, keyPrefix: keyPrefix}, nil
+	})
+}
+
+type s3ChunkUploader struct {
+	client    S3Client
+	bucket    string
+	keyPrefix string
+}
+
+func (uploader *s3ChunkUploader) UploadChunk(ctx context.Context, index int, chunk []byte) error {
+	return uploader.client.PutObject(ctx, uploader.bucket, chunkKey(uploader.keyPrefix, index), chunk)
+}
+
+// S3ContextLoader loads the context data from the S3 object named by the
+// "bucket" and "key" context params, as written by PipesS3ContextInjector.
+type S3ContextLoader struct {
+	Client S3Client
+}
+
+func NewS3ContextLoader(client S3Client) *S3ContextLoader {
+	return &S3ContextLoader{Client: client}
+}
+
+func (loader *S3ContextLoader) LoadContext(params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	return loader.LoadContextContext(context.Background(), params)
+}
+
+// LoadContextContext is like LoadContext but gives up when ctx is done.
+func (loader *S3ContextLoader) LoadContextContext(ctx context.Context, params map[string]json.RawMessage) (*types.PipesContextData, error) {
+	bucket, err := payloadParam(params, "bucket")
+	if err != nil {
+		return nil, err
+	}
+	key, err := payloadParam(params, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := loader.Client.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, &PayloadError{Kind: PayloadErrorKind_Unreadable, Err: err}
+	}
+	defer body.Close()
+
+	return decodeContextData(body)
+}
+
+// chunkKey returns the object key of the index-th message chunk.
+func chunkKey(keyPrefix string, index int) string {
+	return fmt.Sprintf("%s/%d.json", keyPrefix, index)
+}
@@ -0,0 +1,147 @@
+package dagster_pipes
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultGracePeriod is how long HandleSignals waits for the program to
+// close the session itself after a signal arrives.
+const DefaultGracePeriod = 10 * time.Second
+
+// DefaultCloseTimeout is how long HandleSignals waits for the session to
+// close after the grace period before the process exits anyway.
+const DefaultCloseTimeout = 5 * time.Second
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// Signals to handle. Defaults to os.Interrupt and syscall.SIGTERM.
+	Signals []os.Signal
+	// GracePeriod is how long user code gets to wind down after the session
+	// context is cancelled. Defaults to DefaultGracePeriod.
+	GracePeriod time.Duration
+	// CloseTimeout bounds closing the session after the grace period, which
+	// waits for in-flight reports and writes the closed message. Defaults to
+	// DefaultCloseTimeout.
+	CloseTimeout time.Duration
+}
+
+// InterruptedError is the cause of the session context, and the error
+// reported to Dagster, when the process is interrupted by a signal.
+type InterruptedError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted by signal: %s", e.Signal)
+}
+
+// HandleSignals makes the session shut down gracefully when the process
+// receives one of the configured signals, as happens when Kubernetes or
+// Dagster cancels the run. options may be nil.
+//
+// When a signal arrives the context returned by Context is cancelled with an
+// InterruptedError. If the session is still open after the grace period, it
+// is closed with a PipesException describing the interruption and the
+// process exits with status 128 plus the signal number. The process also
+// exits when closing takes longer than the close timeout, as happens when a
+// write is stuck. If the program closes the session within the grace period,
+// it keeps running and decides on its own when to exit. Either way, closing
+// the session flushes the channel, so buffered messages are written before
+// the process exits.
+//
+// Calling HandleSignals again replaces the previous handler, so a program
+// run by Run can install its own options. The returned function stops
+// handling signals.
+func (context *PipesContext) HandleSignals(options *SignalOptions) (stop func()) {
+	if options == nil {
+		options = &SignalOptions{}
+	}
+	signals := options.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	gracePeriod := options.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultGracePeriod
+	}
+	closeTimeout := options.CloseTimeout
+	if closeTimeout <= 0 {
+		closeTimeout = DefaultCloseTimeout
+	}
+
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(received, signals...)
+
+	var stopOnce sync.Once
+	stop = func() {
+		stopOnce.Do(func() {
+			signal.Stop(received)
+			close(done)
+		})
+	}
+
+	context.mu.Lock()
+	previous := context.stopSignals
+	context.stopSignals = stop
+	context.mu.Unlock()
+	if previous != nil {
+		previous()
+	}
+
+	go func() {
+		select {
+		case sig := <-received:
+			code, exit := context.interrupt(sig, gracePeriod, closeTimeout)
+			stop()
+			if exit {
+				os.Exit(code)
+			}
+		case <-done:
+		}
+	}()
+	return stop
+}
+
+// interrupt cancels the session, waits up to gracePeriod for it to be closed
+// and closes it with an exception otherwise, giving up after closeTimeout. It
+// reports whether the process should exit, and with which code.
+func (context *PipesContext) interrupt(sig os.Signal, gracePeriod, closeTimeout time.Duration) (code int, exit bool) {
+	session := context.getSession()
+	interrupted := &InterruptedError{Signal: sig}
+	session.cancel(interrupted)
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-session.done:
+		return 0, false
+	case <-timer.C:
+	}
+
+	// Close waits for in-flight reports, so a stuck write would keep the
+	// process alive forever.
+	closed := make(chan error, 1)
+	go func() {
+		closed <- context.Close(PipesExceptionError(interrupted))
+	}()
+	timer.Reset(closeTimeout)
+	select {
+	case err := <-closed:
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "dagster pipes: cannot close session: %v\n", err)
+		}
+	case <-timer.C:
+		fmt.Fprintf(os.Stderr, "dagster pipes: session did not close within %s\n", closeTimeout)
+	}
+
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s), true
+	}
+	return 1, true
+}
@@ -0,0 +1,95 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestPipesContext_Interrupt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("closes the session after the grace period", func(t *testing.T) {
+		t.Parallel()
+		file, pipesContext := singleAssetFileAndContext(t)
+
+		code, exit := pipesContext.interrupt(syscall.SIGTERM, 10*time.Millisecond, time.Minute)
+		require.True(t, exit)
+		require.Equal(t, 128+int(syscall.SIGTERM), code)
+
+		var interrupted *InterruptedError
+		require.ErrorAs(t, context.Cause(pipesContext.Context()), &interrupted)
+		require.Equal(t, syscall.SIGTERM, interrupted.Signal)
+
+		content, err := os.ReadFile(file.Path)
+		require.NoError(t, err)
+
+		var message types.PipesMessage
+		err = json.Unmarshal(content, &message)
+		require.NoError(t, err)
+		require.Equal(t, types.Closed, message.Method)
+		require.Equal(t, "*dagster_pipes.InterruptedError", message.Params["name"])
+		require.Equal(t, "interrupted by signal: terminated", message.Params["message"])
+	})
+
+	t.Run("lets the program close the session", func(t *testing.T) {
+		t.Parallel()
+		_, pipesContext := singleAssetFileAndContext(t)
+
+		go func() {
+			<-pipesContext.Context().Done()
+			pipesContext.Close(nil)
+		}()
+
+		_, exit := pipesContext.interrupt(os.Interrupt, time.Minute, time.Minute)
+		require.False(t, exit)
+	})
+
+	t.Run("writes buffered messages in one block", func(t *testing.T) {
+		t.Parallel()
+		stream := &countingWriter{}
+		pipesContext := &PipesContext{
+			Data:    &types.PipesContextData{AssetKeys: []string{"asset1"}},
+			Channel: NewBufferedStreamChannel(stream),
+		}
+		require.NoError(t, pipesContext.ReportCustomMessage("before the signal"))
+
+		_, exit := pipesContext.interrupt(syscall.SIGTERM, 10*time.Millisecond, time.Minute)
+		require.True(t, exit)
+		require.Equal(t, 1, stream.writes)
+		require.Equal(t, 2, strings.Count(stream.String(), "\n"))
+	})
+
+	t.Run("exits when closing the session is stuck", func(t *testing.T) {
+		t.Parallel()
+		channel := &recordingChannel{release: make(chan struct{})}
+		t.Cleanup(func() { close(channel.release) })
+		pipesContext := &PipesContext{
+			Data:    &types.PipesContextData{AssetKeys: []string{"asset1"}},
+			Channel: channel,
+		}
+
+		code, exit := pipesContext.interrupt(syscall.SIGTERM, 10*time.Millisecond, 10*time.Millisecond)
+		require.True(t, exit)
+		require.Equal(t, 128+int(syscall.SIGTERM), code)
+	})
+}
+
+// countingWriter records how many writes it received.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
@@ -0,0 +1,172 @@
+package dagster_pipes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+// SlogLevelCritical is the slog level mapped to the CRITICAL Dagster level.
+// Records at or above it are reported as critical.
+const SlogLevelCritical = slog.LevelError + 4
+
+// SlogHandlerOptions configures a SlogHandler.
+type SlogHandlerOptions struct {
+	// Level is the minimum level forwarded to Dagster. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+	// Tee, when set, also receives every record, for example to keep printing
+	// logs to stderr while running locally.
+	Tee slog.Handler
+}
+
+// SlogHandler is a slog.Handler that forwards records to Dagster as log
+// messages. Attributes and groups are rendered into the message as
+// key=value pairs after the record message.
+//
+// Route all existing slog calls into the Dagster event log with:
+//
+//	slog.SetDefault(slog.New(dagster_pipes.NewSlogHandler(context, nil)))
+type SlogHandler struct {
+	logger *PipesLogger
+	level  slog.Leveler
+	tee    slog.Handler
+
+	// attrs holds the attributes added with WithAttrs, already rendered.
+	attrs string
+	// prefix is the key prefix built from the groups opened with WithGroup.
+	prefix string
+}
+
+// NewSlogHandler creates a SlogHandler that logs through context. options
+// may be nil.
+func NewSlogHandler(context *PipesContext, options *SlogHandlerOptions) *SlogHandler {
+	if options == nil {
+		options = &SlogHandlerOptions{}
+	}
+	level := options.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &SlogHandler{
+		logger: NewPipesLogger(context),
+		level:  level,
+		tee:    options.Tee,
+	}
+}
+
+func (handler *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= handler.level.Level() {
+		return true
+	}
+	return handler.tee != nil && handler.tee.Enabled(ctx, level)
+}
+
+// Handle sends record to Dagster. It ignores ctx being done, like the
+// handlers of log/slog, so that logging an error about a cancelled operation
+// still reaches the event log.
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	if record.Level >= handler.level.Level() {
+		errs = append(errs, handler.logger.Log(PipesLogLevelFromSlog(record.Level), handler.render(record)))
+	}
+	if handler.tee != nil && handler.tee.Enabled(ctx, record.Level) {
+		errs = append(errs, handler.tee.Handle(ctx, record))
+	}
+	return errors.Join(errs...)
+}
+
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return handler
+	}
+	clone := *handler
+	var b strings.Builder
+	b.WriteString(handler.attrs)
+	for _, attr := range attrs {
+		appendAttr(&b, handler.prefix, attr)
+	}
+	clone.attrs = b.String()
+	if handler.tee != nil {
+		clone.tee = handler.tee.WithAttrs(attrs)
+	}
+	return &clone
+}
+
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	clone := *handler
+	clone.prefix = handler.prefix + name + "."
+	if handler.tee != nil {
+		clone.tee = handler.tee.WithGroup(name)
+	}
+	return &clone
+}
+
+func (handler *SlogHandler) render(record slog.Record) string {
+	var b strings.Builder
+	b.WriteString(record.Message)
+	b.WriteString(handler.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&b, handler.prefix, attr)
+		return true
+	})
+	return b.String()
+}
+
+// PipesLogLevelFromSlog maps a slog level to the closest Dagster log level.
+func PipesLogLevelFromSlog(level slog.Level) types.PipesLogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return types.Debug
+	case level < slog.LevelWarn:
+		return types.Info
+	case level < slog.LevelError:
+		return types.Warning
+	case level < SlogLevelCritical:
+		return types.PipesLogLevelERROR
+	default:
+		return types.Critical
+	}
+}
+
+// appendAttr renders attr as " key=value", expanding groups into dotted keys.
+func appendAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			appendAttr(b, prefix, groupAttr)
+		}
+		return
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(quoteIfNeeded(prefix + attr.Key))
+	b.WriteByte('=')
+	b.WriteString(quoteIfNeeded(attr.Value.String()))
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package dagster_pipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wingyplus/dagster-pipes-go/types"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Parallel()
+
+	readMessages := func(t *testing.T, path string) []types.PipesMessage {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		var messages []types.PipesMessage
+		for _, line := range bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			var message types.PipesMessage
+			err = json.Unmarshal(line, &message)
+			require.NoError(t, err)
+			messages = append(messages, message)
+		}
+		return messages
+	}
+
+	t.Run("renders attributes and groups", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+		logger := slog.New(NewSlogHandler(context, nil))
+
+		logger.With("run", "012345").WithGroup("job").Info("started", "name", "my job", slog.Group("retry", "count", 1))
+
+		messages := readMessages(t, file.Path)
+		require.Len(t, messages, 1)
+		require.Equal(t, map[string]any{
+			"level":   "INFO",
+			"message": `started run=012345 job.name="my job" job.retry.count=1`,
+		}, messages[0].Params)
+	})
+
+	t.Run("maps levels", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+		logger := slog.New(NewSlogHandler(context, &SlogHandlerOptions{Level: slog.LevelDebug}))
+
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+		logger.Log(t.Context(), SlogLevelCritical, "critical")
+
+		messages := readMessages(t, file.Path)
+		var levels []any
+		for _, message := range messages {
+			levels = append(levels, message.Params["level"])
+		}
+		require.Equal(t, []any{"DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}, levels)
+	})
+
+	t.Run("tees to another handler", func(t *testing.T) {
+		t.Parallel()
+		file, context := singleAssetFileAndContext(t)
+		var buf bytes.Buffer
+		tee := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+		logger := slog.New(NewSlogHandler(context, &SlogHandlerOptions{Tee: tee}))
+
+		logger.Debug("local only")
+		logger.Info("both")
+
+		messages := readMessages(t, file.Path)
+		require.Len(t, messages, 1)
+		require.Equal(t, "both", messages[0].Params["message"])
+		require.Contains(t, buf.String(), "msg=\"local only\"")
+		require.Contains(t, buf.String(), "msg=both")
+	})
+	t.Run("logs with a cancelled context", func(t *testing.T) {
+		t.Parallel()
+		file, pipesContext := singleAssetFileAndContext(t)
+		logger := slog.New(NewSlogHandler(pipesContext, nil))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		logger.ErrorContext(ctx, "operation cancelled")
+
+		messages := readMessages(t, file.Path)
+		require.Len(t, messages, 1)
+		require.Equal(t, "operation cancelled", messages[0].Params["message"])
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"reflect"
@@ -21,8 +22,8 @@ func main() {
 	customPayload := flag.String("custom-payload", "", "")
 	reportAssetCheck := flag.String("report-asset-check", "", "")
 	reportAssetMaterialization := flag.String("report-asset-materialization", "", "")
-	// messageWriter := flag.String("message-writer", "", "")
-	// contextLoader := flag.String("context-loader", "", "")
+	messageWriter := flag.String("message-writer", "", "")
+	contextLoader := flag.String("context-loader", "", "")
 
 	flag.Parse()
 
@@ -37,10 +38,16 @@ func main() {
 		os.Setenv(dagster_pipes.DAGSTER_PIPES_MESSAGES_ENV_VAR, *messages)
 	}
 
-	pipesCtx, err := dagster_pipes.OpenDasterPipes()
+	pipesCtx, err := openPipes(*contextLoader, *messageWriter)
 	if err != nil {
 		panic(err)
 	}
+	if _, err := pipesCtx.ForwardExternalStreams(nil); err != nil {
+		panic(err)
+	}
+	// Closing the context stops forwarding the streams, so that the output
+	// written last is forwarded before the process exits.
+	defer pipesCtx.Close(nil)
 
 	if jb := *jobName; len(jb) > 0 {
 		if *pipesCtx.Data.JobName != jb {
@@ -67,18 +74,82 @@ func main() {
 
 	switch *testName {
 	case "test_message_log":
-		testMessageLog(*ctx)
+		testMessageLog(pipesCtx)
 	case "test_message_report_custom_message":
 		testMessageReportCustomMessage(pipesCtx, *customPayload)
 	case "test_message_report_asset_materialization":
 		testMessageReportAssetMaterialization(pipesCtx, *reportAssetMaterialization)
 	case "test_message_report_asset_check":
 		testMessageReportAssetCheck(pipesCtx, *reportAssetCheck)
+	case "test_message_log_external_stream":
+		testMessageLogExternalStream()
+	}
+}
+
+// openPipes opens the pipes session with the context loader and message
+// writer named by the --context-loader and --message-writer flags.
+//
+// S3 is not supported: the module does not depend on the AWS SDK, so there is
+// no S3Client to build the S3 writer and loader with, and S3 conformance is
+// disabled in pipes.toml.
+func openPipes(contextLoaderName, messageWriterName string) (*dagster_pipes.PipesContext, error) {
+	var contextLoader dagster_pipes.LoadContext
+	switch contextLoaderName {
+	case "", "default":
+		contextLoader = dagster_pipes.NewDefaultContextLoader()
+	case "dbfs", "databricks":
+		contextLoader = dagster_pipes.NewDBFSContextLoader(dagster_pipes.DefaultDBFSRoot)
+	default:
+		return nil, fmt.Errorf("unsupported context loader %q", contextLoaderName)
+	}
+
+	var messageWriter dagster_pipes.MessageWriter
+	switch messageWriterName {
+	case "", "default":
+		messageWriter = dagster_pipes.NewDefaultMessageWriter()
+	case "dbfs", "databricks":
+		messageWriter = dagster_pipes.NewDBFSMessageWriter(dagster_pipes.DefaultDBFSRoot)
+	default:
+		return nil, fmt.Errorf("unsupported message writer %q", messageWriterName)
+	}
+
+	paramsLoader := dagster_pipes.NewEnvVarLoader()
+	contextParams, err := paramsLoader.LoadContextParams()
+	if err != nil {
+		return nil, err
+	}
+	messageParams, err := paramsLoader.LoadMessageParams()
+	if err != nil {
+		return nil, err
+	}
+	contextData, err := contextLoader.LoadContext(contextParams)
+	if err != nil {
+		return nil, err
+	}
+	return dagster_pipes.NewPipesContext(contextData, messageParams, messageWriter)
+}
+
+func testMessageLog(ctx *dagster_pipes.PipesContext) {
+	logs := []struct {
+		log     func(string) error
+		message string
+	}{
+		{ctx.Log().Debug, "Debug message"},
+		{ctx.Log().Info, "Info message"},
+		{ctx.Log().Warning, "Warning message"},
+		{ctx.Log().Error, "Error message"},
+		{ctx.Log().Critical, "Critical message"},
+	}
+	for _, l := range logs {
+		if err := l.log(l.message); err != nil {
+			panic(err)
+		}
 	}
 }
 
-func testMessageLog(ctx string) {
-	panic("unimplemented")
+func testMessageLogExternalStream() {
+	fmt.Fprintln(os.Stdout, "Writing this to stdout")
+	fmt.Fprintln(os.Stderr, "And this to stderr")
 }
 
 func testMessageReportCustomMessage(ctx *dagster_pipes.PipesContext, customPayload string) {
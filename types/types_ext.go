@@ -32,3 +32,10 @@ func NewMessage(method Method, params map[string]any) *PipesMessage {
 		Params:              params,
 	}
 }
+
+// LogExternalStream is the method of messages carrying output that the
+// external process wrote to its stdout or stderr.
+//
+// It is part of the Dagster Pipes protocol but missing from the generated
+// Method constants.
+const LogExternalStream Method = "log_external_stream"